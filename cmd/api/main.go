@@ -2,15 +2,24 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"socialnetwork/config"
 	"socialnetwork/docs"
 	"socialnetwork/internal/delivery/http/handler"
+	"socialnetwork/internal/delivery/realtime"
+	"socialnetwork/internal/delivery/websocket"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
 	"socialnetwork/internal/repository/postgres"
+	redisrepo "socialnetwork/internal/repository/redis"
+	"socialnetwork/internal/repository/storage"
 	"socialnetwork/internal/usecase"
+	"socialnetwork/migrations"
 	"socialnetwork/pkg/database"
 	"syscall"
 	"time"
@@ -40,48 +49,146 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
+	// Load configuration
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+
 	// Swagger initialization
 	docs.SwaggerInfo.BasePath = "/api"
 
 	// Initialize database connection
-	db, err := database.NewPostgresConnection(&database.PostgresConfig{
-		Host:     "localhost",
-		Port:     5433,
-		User:     "postgres",
-		Password: "postgres",
-		DBName:   "socialnetwork",
-		SSLMode:  "disable",
-	})
+	db, err := database.NewPostgresConnection(&cfg.Postgres)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	// Initialize repositories
-	userRepo := postgres.NewUserRepository(db)
-	postRepo := postgres.NewPostRepository(db)
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to get database instance:", err)
+	}
+	migrator, err := database.NewMigrator(sqlDB, migrations.FS)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// JWT secret key
-	secretKey := "your-secret-key"
+	// Subcommands run instead of the server
+	if len(cfg.Args) > 0 {
+		if cfg.Args[0] != "migrate" {
+			log.Fatalf("Unknown command %q", cfg.Args[0])
+		}
+		if err := runMigrate(migrator, cfg.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Refuse to serve against an outdated schema
+	if err := migrator.CheckSchema(context.Background()); err != nil {
+		log.Fatal(err, "; run `migrate up` first")
+	}
+
+	// Initialize Redis connection
+	redisClient, err := database.NewRedisConnection(&cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis:", err)
+	}
+
+	// Initialize repositories, reading users and posts through the cache
+	blockRepo := postgres.NewBlockRepository(db)
+	muteRepo := postgres.NewMuteRepository(db)
+	userRepo := redisrepo.NewCachedUserRepository(postgres.NewUserRepository(db), redisClient, cfg.Cache.EntityTTL)
+	postRepo := redisrepo.NewCachedPostRepository(postgres.NewPostRepository(db), redisClient, cfg.Cache.EntityTTL, cfg.Cache.FeedTTL)
+	feedCache := redisrepo.NewFeedCache(redisClient)
+	followRepo := postgres.NewFollowRepository(db)
+	likeRepo := postgres.NewLikeRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+	tokenRepo := redisrepo.NewTokenRepository(redisClient)
+	mediaRepo := postgres.NewMediaRepository(db)
+	hashtagRepo := postgres.NewHashtagRepository(db)
+	mentionRepo := postgres.NewMentionRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	conversationRepo := postgres.NewConversationRepository(db)
+	messageRepo := postgres.NewMessageRepository(db)
+
+	// Initialize media storage
+	mediaStorage, err := newMediaStorage(&cfg.Media)
+	if err != nil {
+		log.Fatal("Failed to initialize media storage:", err)
+	}
+
+	// Background image processing
+	mediaProcessor := usecase.NewMediaProcessor(mediaRepo, mediaStorage, 100)
+	trendingRefresher := usecase.NewTrendingRefresher(hashtagRepo)
+
+	// Domain events, turned into notifications and realtime pushes shared
+	// with other instances through Redis
+	eventBus := usecase.NewEventBus(1000)
+	pushBroker := redisrepo.NewPushBroker(redisClient)
+	eventBus.Subscribe(usecase.NewNotifier(notificationRepo, blockRepo, postRepo, eventBus).Handle)
+	eventBus.Subscribe(usecase.NewPusher(pushBroker, followRepo, conversationRepo, muteRepo).Handle)
+	hub := realtime.NewHub(pushBroker, 64)
 
 	// Initialize use cases
-	authUseCase := usecase.NewAuthUseCase(userRepo, secretKey)
-	userUseCase := usecase.NewUserUseCase(userRepo)
-	postUseCase := usecase.NewPostUseCase(postRepo, userRepo)
+	authUseCase := usecase.NewAuthUseCase(userRepo, tokenRepo, cfg.JWT.SecretKey, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
+	userUseCase := usecase.NewUserUseCase(userRepo, mediaRepo, blockRepo, feedCache)
+	postUseCase := usecase.NewPostUseCase(postRepo, userRepo, likeRepo, mediaRepo, hashtagRepo, mentionRepo, commentRepo, eventBus, cfg.Post.EditWindow)
+	followUseCase := usecase.NewFollowUseCase(followRepo, userRepo, mediaRepo, blockRepo, feedCache, eventBus)
+	likeUseCase := usecase.NewLikeUseCase(likeRepo, postRepo, mediaRepo, eventBus)
+	commentUseCase := usecase.NewCommentUseCase(commentRepo, postRepo, userRepo, mentionRepo, blockRepo, eventBus)
+	mediaUseCase := usecase.NewMediaUseCase(mediaRepo, mediaStorage, mediaProcessor, int64(cfg.Media.MaxSize))
+	hashtagUseCase := usecase.NewHashtagUseCase(hashtagRepo)
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, mediaRepo)
+	blockUseCase := usecase.NewBlockUseCase(blockRepo, muteRepo, userRepo, mediaRepo, feedCache)
+	conversationUseCase := usecase.NewConversationUseCase(conversationRepo, messageRepo, userRepo, mediaRepo, blockRepo, eventBus)
+
+	// Authentication middleware, rejecting revoked tokens
+	authMiddleware := middleware.JWTMiddleware(cfg.JWT.SecretKey, authUseCase)
+	optionalAuthMiddleware := middleware.OptionalJWTMiddleware(cfg.JWT.SecretKey, authUseCase)
 
 	// Initialize HTTP handlers
-	authHandler := handler.NewAuthHandler(authUseCase)
-	userHandler := handler.NewUserHandler(userUseCase)
-	postHandler := handler.NewPostHandler(postUseCase, secretKey)
+	authHandler := handler.NewAuthHandler(authUseCase, authMiddleware)
+	userHandler := handler.NewUserHandler(userUseCase, authMiddleware, optionalAuthMiddleware)
+	postHandler := handler.NewPostHandler(postUseCase, authMiddleware)
+	followHandler := handler.NewFollowHandler(followUseCase, authMiddleware)
+	likeHandler := handler.NewLikeHandler(likeUseCase, authMiddleware)
+	commentHandler := handler.NewCommentHandler(commentUseCase, authMiddleware)
+	mediaHandler := handler.NewMediaHandler(mediaUseCase, authMiddleware)
+	hashtagHandler := handler.NewHashtagHandler(hashtagUseCase, postUseCase, authMiddleware)
+	mentionHandler := handler.NewMentionHandler(postUseCase, authMiddleware)
+	notificationHandler := handler.NewNotificationHandler(notificationUseCase, authMiddleware)
+	conversationHandler := handler.NewConversationHandler(conversationUseCase, authMiddleware)
+	blockHandler := handler.NewBlockHandler(blockUseCase, authMiddleware)
+	websocketHandler := websocket.NewHandler(hub, cfg.JWT.SecretKey, authUseCase)
+	feedStreamHandler := handler.NewFeedStreamHandler(postUseCase, hub, authMiddleware)
 
 	// Initialize Gin router
-	router := gin.Default()
+	if !cfg.IsDevelopment() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	router.Use(middleware.Logger(), gin.Recovery())
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Cache hit/miss counters, kept off public deployments
+	if cfg.IsDevelopment() {
+		router.GET("/debug/cache", func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json", []byte(expvar.Get("cache").String()))
+		})
+	}
+
 	// Serve static files
 	router.Static("/static", "./web/static")
 	router.StaticFile("/", "./web/static/index.html")
+	if cfg.Media.Storage == config.MediaStorageLocal {
+		router.Static("/media", cfg.Media.LocalDir)
+	}
+
+	// Realtime pushes
+	websocketHandler.Register(router)
 
 	// API routes
 	api := router.Group("/api")
@@ -89,17 +196,32 @@ func main() {
 		authHandler.Register(api)
 		userHandler.Register(api)
 		postHandler.Register(api)
+		followHandler.Register(api)
+		likeHandler.Register(api)
+		commentHandler.Register(api)
+		mediaHandler.Register(api)
+		hashtagHandler.Register(api)
+		mentionHandler.Register(api)
+		notificationHandler.Register(api)
+		conversationHandler.Register(api)
+		blockHandler.Register(api)
+		feedStreamHandler.Register(api)
 	}
 
 	// Create server
 	server := &http.Server{
-		Addr:    ":8080",
+		Addr:    fmt.Sprintf(":%d", cfg.App.Port),
 		Handler: router,
 	}
 
 	// Server run context
 	serverCtx, serverStopCtx := context.WithCancel(context.Background())
 
+	go mediaProcessor.Run(serverCtx, cfg.Media.Workers)
+	go trendingRefresher.Run(serverCtx, cfg.Trending.RefreshInterval)
+	go eventBus.Run(serverCtx)
+	go hub.Run(serverCtx)
+
 	// Listen for syscall signals for process to interrupt/quit
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
@@ -107,7 +229,8 @@ func main() {
 		<-sig
 
 		// Shutdown signal with grace period of 30 seconds
-		shutdownCtx, _ := context.WithTimeout(serverCtx, 30*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(serverCtx, 30*time.Second)
+		defer cancel()
 
 		go func() {
 			<-shutdownCtx.Done()
@@ -125,7 +248,7 @@ func main() {
 	}()
 
 	// Run the server
-	fmt.Println("Server is running on http://localhost:8080")
+	fmt.Printf("Server is running on http://localhost:%d\n", cfg.App.Port)
 	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		log.Fatal(err)
@@ -134,3 +257,17 @@ func main() {
 	// Wait for server context to be stopped
 	<-serverCtx.Done()
 }
+
+func newMediaStorage(cfg *config.MediaConfig) (domain.MediaStorage, error) {
+	if cfg.Storage == config.MediaStorageS3 {
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PublicURL: cfg.S3PublicURL,
+		})
+	}
+	return storage.NewLocalStorage(cfg.LocalDir, cfg.BaseURL)
+}
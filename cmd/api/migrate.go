@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"socialnetwork/pkg/database"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: migrate up [N] | down [N] | status | goto VERSION"
+
+// runMigrate implements the migrate subcommand. up applies all pending
+// migrations (or the next N), down reverts the last one (or the last N).
+func runMigrate(migrator *database.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up", "down":
+		n, err := migrateCount(args[1:])
+		if err != nil {
+			return err
+		}
+		run := migrator.Up
+		if args[0] == "down" {
+			run = migrator.Down
+		}
+		versions, err := run(ctx, n)
+		for _, v := range versions {
+			fmt.Printf("%s %d\n", args[0], v)
+		}
+		if err == nil && len(versions) == 0 {
+			fmt.Println("no change")
+		}
+		return err
+
+	case "goto":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.Goto(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("at version %d\n", version)
+		return nil
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+	}
+
+	return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+}
+
+func migrateCount(args []string) (int, error) {
+	switch len(args) {
+	case 0:
+		return 0, nil
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid count %q", args[0])
+		}
+		return n, nil
+	}
+	return 0, errors.New(migrateUsage)
+}
@@ -0,0 +1,354 @@
+// Package config loads the application settings from command-line flags,
+// environment variables and an optional .env file, in that order of
+// precedence.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"socialnetwork/pkg/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	// defaultJWTSecret is only accepted in development
+	defaultJWTSecret = "your-secret-key"
+)
+
+type Config struct {
+	App      AppConfig
+	Postgres database.PostgresConfig
+	Redis    database.RedisConfig
+	JWT      JWTConfig
+	Cache    CacheConfig
+	Media    MediaConfig
+	Trending TrendingConfig
+	Post     PostConfig
+
+	// Args holds the positional arguments left after the flags, naming a
+	// subcommand such as "migrate up".
+	Args []string
+}
+
+type AppConfig struct {
+	Name string
+	Env  string
+	Port int
+}
+
+type JWTConfig struct {
+	SecretKey       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// CacheConfig controls the Redis read-through cache. A TTL of zero disables
+// caching for that kind of entry.
+type CacheConfig struct {
+	EntityTTL time.Duration
+	FeedTTL   time.Duration
+}
+
+const (
+	MediaStorageLocal = "local"
+	MediaStorageS3    = "s3"
+)
+
+// MediaConfig selects where uploaded media is stored. LocalDir and BaseURL
+// apply to local storage, the S3 fields to S3-compatible storage.
+type MediaConfig struct {
+	Storage  string
+	MaxSize  int
+	Workers  int
+	LocalDir string
+	BaseURL  string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PublicURL string
+}
+
+// TrendingConfig controls the background job that recomputes trending
+// hashtags.
+type TrendingConfig struct {
+	RefreshInterval time.Duration
+}
+
+// PostConfig controls how posts may change. An EditWindow of zero lets
+// authors edit their posts at any time.
+type PostConfig struct {
+	EditWindow time.Duration
+}
+
+func (c *Config) IsDevelopment() bool {
+	return c.App.Env == EnvDevelopment
+}
+
+// setting binds one configuration value to its environment variable and flag.
+type setting struct {
+	env   string
+	flag  string
+	def   string
+	usage string
+	set   func(value string) error
+}
+
+func stringVar(target *string) func(string) error {
+	return func(value string) error {
+		*target = value
+		return nil
+	}
+}
+
+func intVar(target *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*target = n
+		return nil
+	}
+}
+
+func durationVar(target *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*target = d
+		return nil
+	}
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"APP_NAME", "app-name", "socialnetwork", "application name", stringVar(&c.App.Name)},
+		{"APP_ENV", "env", EnvProduction, "environment (development, staging, production)", stringVar(&c.App.Env)},
+		{"APP_PORT", "port", "8080", "HTTP port to listen on", intVar(&c.App.Port)},
+
+		{"DB_HOST", "db-host", "localhost", "PostgreSQL host", stringVar(&c.Postgres.Host)},
+		{"DB_PORT", "db-port", "5433", "PostgreSQL port", intVar(&c.Postgres.Port)},
+		{"DB_USER", "db-user", "postgres", "PostgreSQL user", stringVar(&c.Postgres.User)},
+		{"DB_PASSWORD", "db-password", "", "PostgreSQL password", stringVar(&c.Postgres.Password)},
+		{"DB_NAME", "db-name", "socialnetwork", "PostgreSQL database name", stringVar(&c.Postgres.DBName)},
+		{"DB_SSL_MODE", "db-ssl-mode", "disable", "PostgreSQL SSL mode", stringVar(&c.Postgres.SSLMode)},
+
+		{"REDIS_HOST", "redis-host", "localhost", "Redis host", stringVar(&c.Redis.Host)},
+		{"REDIS_PORT", "redis-port", "6380", "Redis port", intVar(&c.Redis.Port)},
+		{"REDIS_PASSWORD", "redis-password", "", "Redis password", stringVar(&c.Redis.Password)},
+		{"REDIS_DB", "redis-db", "0", "Redis database number", intVar(&c.Redis.DB)},
+
+		{"JWT_SECRET_KEY", "jwt-secret-key", defaultJWTSecret, "secret used to sign access tokens", stringVar(&c.JWT.SecretKey)},
+		{"JWT_ACCESS_TOKEN_TTL", "jwt-access-token-ttl", "15m", "lifetime of access tokens", durationVar(&c.JWT.AccessTokenTTL)},
+		{"JWT_REFRESH_TOKEN_TTL", "jwt-refresh-token-ttl", "720h", "lifetime of refresh tokens", durationVar(&c.JWT.RefreshTokenTTL)},
+
+		{"CACHE_ENTITY_TTL", "cache-entity-ttl", "5m", "lifetime of cached users and posts (0 disables)", durationVar(&c.Cache.EntityTTL)},
+		{"CACHE_FEED_TTL", "cache-feed-ttl", "30s", "lifetime of cached first feed pages (0 disables)", durationVar(&c.Cache.FeedTTL)},
+
+		{"MEDIA_STORAGE", "media-storage", MediaStorageLocal, "media storage backend (local, s3)", stringVar(&c.Media.Storage)},
+		{"MEDIA_MAX_SIZE", "media-max-size", "10485760", "largest accepted upload in bytes", intVar(&c.Media.MaxSize)},
+		{"MEDIA_WORKERS", "media-workers", "2", "number of background image processing workers", intVar(&c.Media.Workers)},
+		{"MEDIA_LOCAL_DIR", "media-local-dir", "./uploads", "directory for locally stored media", stringVar(&c.Media.LocalDir)},
+		{"MEDIA_BASE_URL", "media-base-url", "/media", "URL prefix locally stored media is served under", stringVar(&c.Media.BaseURL)},
+		{"S3_ENDPOINT", "s3-endpoint", "", "S3-compatible API endpoint", stringVar(&c.Media.S3Endpoint)},
+		{"S3_REGION", "s3-region", "us-east-1", "S3 region", stringVar(&c.Media.S3Region)},
+		{"S3_BUCKET", "s3-bucket", "", "S3 bucket for media", stringVar(&c.Media.S3Bucket)},
+		{"S3_ACCESS_KEY", "s3-access-key", "", "S3 access key", stringVar(&c.Media.S3AccessKey)},
+		{"S3_SECRET_KEY", "s3-secret-key", "", "S3 secret key", stringVar(&c.Media.S3SecretKey)},
+		{"S3_PUBLIC_URL", "s3-public-url", "", "public URL of the media bucket (defaults to the bucket under S3_ENDPOINT)", stringVar(&c.Media.S3PublicURL)},
+
+		{"TRENDING_REFRESH_INTERVAL", "trending-refresh-interval", "5m", "how often trending hashtags are recomputed", durationVar(&c.Trending.RefreshInterval)},
+
+		{"POST_EDIT_WINDOW", "post-edit-window", "0", "how long after publishing posts can be edited (0 for always)", durationVar(&c.Post.EditWindow)},
+	}
+}
+
+// Load builds the configuration from args (usually os.Args[1:]), the
+// environment and the .env file named by the -env-file flag. A missing .env
+// file is not an error.
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	envFile := fs.String("env-file", ".env", "path to an optional .env file")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.flag] = fs.String(s.flag, "", fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.def))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	fileValues, err := readEnvFile(*envFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range settings {
+		value := s.def
+		if v, ok := fileValues[s.env]; ok {
+			value = v
+		}
+		if v, ok := os.LookupEnv(s.env); ok {
+			value = v
+		}
+		if explicit[s.flag] {
+			value = *flagValues[s.flag]
+		}
+
+		if err := s.set(value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", s.env, value, err)
+		}
+	}
+
+	cfg.Args = fs.Args()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that every required value is present and sane.
+func (c *Config) Validate() error {
+	var errs []error
+
+	required := []struct{ key, value string }{
+		{"APP_ENV", c.App.Env},
+		{"DB_HOST", c.Postgres.Host},
+		{"DB_USER", c.Postgres.User},
+		{"DB_NAME", c.Postgres.DBName},
+		{"REDIS_HOST", c.Redis.Host},
+		{"JWT_SECRET_KEY", c.JWT.SecretKey},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", r.key))
+		}
+	}
+
+	ports := []struct {
+		key  string
+		port int
+	}{
+		{"APP_PORT", c.App.Port},
+		{"DB_PORT", c.Postgres.Port},
+		{"REDIS_PORT", c.Redis.Port},
+	}
+	for _, p := range ports {
+		if p.port < 1 || p.port > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be between 1 and 65535", p.key))
+		}
+	}
+
+	if c.JWT.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("JWT_ACCESS_TOKEN_TTL must be positive"))
+	}
+	if c.JWT.RefreshTokenTTL <= c.JWT.AccessTokenTTL {
+		errs = append(errs, errors.New("JWT_REFRESH_TOKEN_TTL must be longer than JWT_ACCESS_TOKEN_TTL"))
+	}
+
+	if c.Cache.EntityTTL < 0 {
+		errs = append(errs, errors.New("CACHE_ENTITY_TTL must not be negative"))
+	}
+	if c.Cache.FeedTTL < 0 {
+		errs = append(errs, errors.New("CACHE_FEED_TTL must not be negative"))
+	}
+
+	if c.Trending.RefreshInterval <= 0 {
+		errs = append(errs, errors.New("TRENDING_REFRESH_INTERVAL must be positive"))
+	}
+
+	if c.Post.EditWindow < 0 {
+		errs = append(errs, errors.New("POST_EDIT_WINDOW must not be negative"))
+	}
+
+	if c.Media.MaxSize <= 0 {
+		errs = append(errs, errors.New("MEDIA_MAX_SIZE must be positive"))
+	}
+	if c.Media.Workers <= 0 {
+		errs = append(errs, errors.New("MEDIA_WORKERS must be positive"))
+	}
+	switch c.Media.Storage {
+	case MediaStorageLocal:
+		if c.Media.LocalDir == "" {
+			errs = append(errs, errors.New("MEDIA_LOCAL_DIR is required for local media storage"))
+		}
+	case MediaStorageS3:
+		for _, r := range []struct{ key, value string }{
+			{"S3_ENDPOINT", c.Media.S3Endpoint},
+			{"S3_REGION", c.Media.S3Region},
+			{"S3_BUCKET", c.Media.S3Bucket},
+			{"S3_ACCESS_KEY", c.Media.S3AccessKey},
+			{"S3_SECRET_KEY", c.Media.S3SecretKey},
+		} {
+			if r.value == "" {
+				errs = append(errs, fmt.Errorf("%s is required for s3 media storage", r.key))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MEDIA_STORAGE must be %s or %s", MediaStorageLocal, MediaStorageS3))
+	}
+
+	if !c.IsDevelopment() && c.JWT.SecretKey == defaultJWTSecret {
+		errs = append(errs, fmt.Errorf("JWT_SECRET_KEY must be changed from its default outside %s", EnvDevelopment))
+	}
+
+	return errors.Join(errs...)
+}
+
+// readEnvFile parses KEY=VALUE lines, ignoring blank lines and comments.
+func readEnvFile(path string) (map[string]string, error) {
+	values := map[string]string{}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
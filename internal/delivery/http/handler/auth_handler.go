@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"socialnetwork/internal/middleware"
 	"socialnetwork/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -9,11 +11,13 @@ import (
 
 type AuthHandler struct {
 	authUseCase *usecase.AuthUseCase
+	auth        gin.HandlerFunc
 }
 
-func NewAuthHandler(authUseCase *usecase.AuthUseCase) *AuthHandler {
+func NewAuthHandler(authUseCase *usecase.AuthUseCase, auth gin.HandlerFunc) *AuthHandler {
 	return &AuthHandler{
 		authUseCase: authUseCase,
+		auth:        auth,
 	}
 }
 
@@ -22,6 +26,9 @@ func (h *AuthHandler) Register(router *gin.RouterGroup) {
 	{
 		auth.POST("/register", h.RegisterUser)
 		auth.POST("/login", h.Login)
+		auth.POST("/refresh", h.Refresh)
+		auth.POST("/logout", h.auth, h.Logout)
+		auth.POST("/logout-all", h.auth, h.LogoutAll)
 	}
 }
 
@@ -33,6 +40,7 @@ func (h *AuthHandler) Register(router *gin.RouterGroup) {
 // @Param user body usecase.RegisterRequest true "User registration information"
 // @Success 201 {object} usecase.AuthResponse
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /auth/register [post]
 func (h *AuthHandler) RegisterUser(c *gin.Context) {
 	var req usecase.RegisterRequest
@@ -43,7 +51,7 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 
 	response, err := h.authUseCase.Register(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -74,3 +82,83 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a new refresh token. The presented refresh token can only be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body usecase.RefreshRequest true "Refresh token"
+// @Success 200 {object} usecase.AuthResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req usecase.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authUseCase.Refresh(&req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Logout
+// @Description Revoke the current session, invalidating its access and refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/logout [post]
+// @Security Bearer
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID, err := middleware.GetSessionFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.authUseCase.Logout(sessionID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Logout from all sessions
+// @Description Revoke every access and refresh token issued to the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/logout-all [post]
+// @Security Bearer
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.authUseCase.LogoutAll(userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BlockHandler struct {
+	blockUseCase domain.BlockUseCase
+	auth         gin.HandlerFunc
+}
+
+func NewBlockHandler(blockUseCase domain.BlockUseCase, auth gin.HandlerFunc) *BlockHandler {
+	return &BlockHandler{
+		blockUseCase: blockUseCase,
+		auth:         auth,
+	}
+}
+
+func (h *BlockHandler) Register(router *gin.RouterGroup) {
+	users := router.Group("/users")
+	users.Use(h.auth)
+	{
+		users.POST("/:id/block", h.Block)
+		users.DELETE("/:id/block", h.Unblock)
+		users.POST("/:id/mute", h.Mute)
+		users.DELETE("/:id/mute", h.Unmute)
+		users.GET("/me/blocked", h.GetBlocked)
+		users.GET("/me/muted", h.GetMuted)
+	}
+}
+
+// @Summary Block user
+// @Description Block the user with the given ID. Neither user sees the other's profile, posts or comments any more, follows between them end, and they can no longer follow, like, comment on or message each other.
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /users/{id}/block [post]
+// @Security Bearer
+func (h *BlockHandler) Block(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.blockUseCase.Block(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Unblock user
+// @Description Lift a block on the user with the given ID. Follows ended by the block are not restored.
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /users/{id}/block [delete]
+// @Security Bearer
+func (h *BlockHandler) Unblock(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.blockUseCase.Unblock(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Mute user
+// @Description Mute the user with the given ID, hiding their posts from the current user's feeds. Nothing else changes and they are not told.
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /users/{id}/mute [post]
+// @Security Bearer
+func (h *BlockHandler) Mute(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.blockUseCase.Mute(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Unmute user
+// @Description Stop muting the user with the given ID
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /users/{id}/mute [delete]
+// @Security Bearer
+func (h *BlockHandler) Unmute(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.blockUseCase.Unmute(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get blocked users
+// @Description Get paginated list of users the current user blocked, most recent first
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Users per page (default: 20)"
+// @Success 200 {array} domain.PublicUser
+// @Failure 401 {object} map[string]string
+// @Router /users/me/blocked [get]
+// @Security Bearer
+func (h *BlockHandler) GetBlocked(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.blockUseCase.GetBlocked(userID, page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary Get muted users
+// @Description Get paginated list of users the current user muted, most recent first
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Users per page (default: 20)"
+// @Success 200 {array} domain.PublicUser
+// @Failure 401 {object} map[string]string
+// @Router /users/me/muted [get]
+// @Security Bearer
+func (h *BlockHandler) GetMuted(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.blockUseCase.GetMuted(userID, page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommentHandler struct {
+	commentUseCase domain.CommentUseCase
+	auth           gin.HandlerFunc
+}
+
+func NewCommentHandler(commentUseCase domain.CommentUseCase, auth gin.HandlerFunc) *CommentHandler {
+	return &CommentHandler{
+		commentUseCase: commentUseCase,
+		auth:           auth,
+	}
+}
+
+func (h *CommentHandler) Register(router *gin.RouterGroup) {
+	posts := router.Group("/posts")
+	posts.Use(h.auth)
+	{
+		posts.GET("/:id/comments", h.GetComments)
+		posts.POST("/:id/comments", h.CreateComment)
+		posts.PUT("/:id/comments/:comment_id", h.UpdateComment)
+		posts.DELETE("/:id/comments/:comment_id", h.DeleteComment)
+	}
+}
+
+// @Summary Get post comments
+// @Description Get cursor-paginated top-level comments of a post, each with its full reply tree. Comments by users who blocked, or were blocked by, the current user are left out along with their replies.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Top-level comments per page (default: 10, max: 100)"
+// @Success 200 {object} domain.CommentPage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/comments [get]
+// @Security Bearer
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.commentUseCase.GetComments(viewerID, c.Param("id"), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Create comment
+// @Description Comment on a post, or reply to another comment when parent_id is set
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Param comment body domain.Comment true "Comment content"
+// @Success 201 {object} domain.Comment
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /posts/{id}/comments [post]
+// @Security Bearer
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	var comment domain.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	comment.PostID = postID
+	comment.UserID = parsedUserID
+	comment.Replies = nil
+
+	if err := h.commentUseCase.CreateComment(&comment); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// @Summary Update comment
+// @Description Edit the content of one of the current user's comments
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Param comment body domain.Comment true "Updated comment content"
+// @Success 200 {object} domain.Comment
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /posts/{id}/comments/{comment_id} [put]
+// @Security Bearer
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	var comment domain.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	comment.ID = commentID
+	comment.UserID = parsedUserID
+
+	if err := h.commentUseCase.UpdateComment(&comment); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// @Summary Delete comment
+// @Description Delete a comment as its author or as the owner of the post
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /posts/{id}/comments/{comment_id} [delete]
+// @Security Bearer
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.commentUseCase.DeleteComment(userID, c.Param("comment_id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConversationHandler struct {
+	conversationUseCase domain.ConversationUseCase
+	auth                gin.HandlerFunc
+}
+
+func NewConversationHandler(conversationUseCase domain.ConversationUseCase, auth gin.HandlerFunc) *ConversationHandler {
+	return &ConversationHandler{
+		conversationUseCase: conversationUseCase,
+		auth:                auth,
+	}
+}
+
+func (h *ConversationHandler) Register(router *gin.RouterGroup) {
+	conversations := router.Group("/conversations")
+	conversations.Use(h.auth)
+	{
+		conversations.POST("", h.StartConversation)
+		conversations.GET("", h.GetConversations)
+		conversations.GET("/unread-count", h.CountUnread)
+		conversations.GET("/:id", h.GetConversation)
+		conversations.GET("/:id/messages", h.GetMessages)
+		conversations.POST("/:id/messages", h.SendMessage)
+		conversations.POST("/:id/read", h.MarkRead)
+	}
+}
+
+// @Summary Start conversation
+// @Description Start a conversation with one or more users. Starting an untitled conversation with a single user returns the conversation the two already share, if any.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param conversation body domain.StartConversationRequest true "Participants and optional title"
+// @Success 201 {object} domain.Conversation
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /conversations [post]
+// @Security Bearer
+func (h *ConversationHandler) StartConversation(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req domain.StartConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversation, err := h.conversationUseCase.StartConversation(userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, conversation)
+}
+
+// @Summary Get conversations
+// @Description Get the current user's conversations, most recently active first, with their participants, last message and unread count
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Conversations per page (default: 20)"
+// @Success 200 {array} domain.Conversation
+// @Failure 401 {object} map[string]string
+// @Router /conversations [get]
+// @Security Bearer
+func (h *ConversationHandler) GetConversations(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	page, limit := parsePagination(c, 20)
+
+	conversations, err := h.conversationUseCase.GetConversations(userID, page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+// @Summary Count unread messages
+// @Description Get the number of unread messages across the current user's conversations
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} map[string]int
+// @Failure 401 {object} map[string]string
+// @Router /conversations/unread-count [get]
+// @Security Bearer
+func (h *ConversationHandler) CountUnread(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	count, err := h.conversationUseCase.CountUnread(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// @Summary Get conversation
+// @Description Get a conversation the current user takes part in
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} domain.Conversation
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /conversations/{id} [get]
+// @Security Bearer
+func (h *ConversationHandler) GetConversation(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conversation, err := h.conversationUseCase.GetConversation(userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conversation)
+}
+
+// @Summary Get messages
+// @Description Get cursor-paginated messages of a conversation, newest first
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Conversation ID"
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Messages per page (default: 10, max: 100)"
+// @Success 200 {object} domain.MessagePage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /conversations/{id}/messages [get]
+// @Security Bearer
+func (h *ConversationHandler) GetMessages(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.conversationUseCase.GetMessages(userID, c.Param("id"), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Send message
+// @Description Send a message to a conversation the current user takes part in
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Conversation ID"
+// @Param message body domain.SendMessageRequest true "Message content"
+// @Success 201 {object} domain.Message
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /conversations/{id}/messages [post]
+// @Security Bearer
+func (h *ConversationHandler) SendMessage(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req domain.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.conversationUseCase.SendMessage(userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// @Summary Mark conversation read
+// @Description Mark every message of a conversation as read by the current user
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Conversation ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /conversations/{id}/read [post]
+// @Security Bearer
+func (h *ConversationHandler) MarkRead(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.conversationUseCase.MarkRead(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"socialnetwork/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError writes the status code matching a domain error. Anything that
+// is not a domain error is logged and reported as a generic 500, so internal
+// details never reach the client.
+func respondError(c *gin.Context, err error) {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrValidation):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrConflict):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": domainErr.Message})
+}
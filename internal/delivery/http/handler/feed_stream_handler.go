@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"socialnetwork/internal/delivery/realtime"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// feedStreamKeepAlive is how often an idle stream sends a comment, so
+	// proxies do not time it out
+	feedStreamKeepAlive = 30 * time.Second
+	// feedStreamResumePages bounds how many pages of missed posts are
+	// replayed on resume; clients that were away longer should reload
+	feedStreamResumePages    = 5
+	feedStreamResumePageSize = 100
+	// feedStreamRetry is how long clients wait before reconnecting
+	feedStreamRetry = 3 * time.Second
+)
+
+type FeedStreamHandler struct {
+	postUseCase domain.PostUseCase
+	hub         *realtime.Hub
+	auth        gin.HandlerFunc
+}
+
+func NewFeedStreamHandler(postUseCase domain.PostUseCase, hub *realtime.Hub, auth gin.HandlerFunc) *FeedStreamHandler {
+	return &FeedStreamHandler{
+		postUseCase: postUseCase,
+		hub:         hub,
+		auth:        auth,
+	}
+}
+
+func (h *FeedStreamHandler) Register(router *gin.RouterGroup) {
+	router.GET("/posts/feed/stream", middleware.TokenFromQuery(), h.auth, h.Stream)
+}
+
+// @Summary Stream feed
+// @Description Stream new posts of the home feed as Server-Sent Events, for clients that cannot use WebSockets. Each "post" event carries a post, and its id resumes the stream: reconnecting with a Last-Event-ID header first replays the posts missed since. The token may be passed as a query parameter since EventSource cannot set headers.
+// @Tags posts
+// @Produce text/event-stream
+// @Param Authorization header string false "Bearer <token>"
+// @Param token query string false "Access token, when the header cannot be set"
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Success 200 {object} domain.Post
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /posts/feed/stream [get]
+// @Security Bearer
+func (h *FeedStreamHandler) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var resumeFrom *domain.Cursor
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		if resumeFrom, err = domain.DecodeCursor(lastEventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Subscribe before replaying, so posts created meanwhile are not missed
+	sub := h.hub.Subscribe(uid)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keep nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", feedStreamRetry.Milliseconds())
+	c.Writer.Flush()
+
+	sent := map[uuid.UUID]bool{}
+	if resumeFrom != nil {
+		missed, err := h.missedPosts(userID, resumeFrom)
+		if err != nil {
+			// Headers are out, so the client can only be told to reload
+			c.Render(-1, sse.Event{Event: "reload", Data: err.Error()})
+			return
+		}
+		for _, post := range missed {
+			h.send(c, post)
+			sent[post.ID] = true
+		}
+	}
+
+	keepAlive := time.NewTicker(feedStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		case payload, ok := <-sub.Messages():
+			if !ok {
+				// Dropped for falling behind; the client resumes from its
+				// last event when it reconnects
+				return
+			}
+			var message domain.PushMessage
+			if err := json.Unmarshal(payload, &message); err != nil || message.Type != domain.PushFeedPost {
+				continue
+			}
+			var post domain.Post
+			if err := json.Unmarshal(message.Data, &post); err != nil || sent[post.ID] {
+				continue
+			}
+			h.send(c, &post)
+		}
+	}
+}
+
+// missedPosts returns the home feed posts newer than cursor, oldest first.
+func (h *FeedStreamHandler) missedPosts(userID string, cursor *domain.Cursor) ([]*domain.Post, error) {
+	var missed []*domain.Post
+	query := domain.PageQuery{Cursor: domain.NewCursor(cursor.CreatedAt, cursor.ID, domain.CursorPrev), Limit: feedStreamResumePageSize}
+	for i := 0; i < feedStreamResumePages; i++ {
+		page, err := h.postUseCase.GetHomeFeed(userID, query)
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Posts) == 0 {
+			break
+		}
+		// Pages list newest first
+		for j := len(page.Posts) - 1; j >= 0; j-- {
+			missed = append(missed, page.Posts[j])
+		}
+		if query.Cursor, err = domain.DecodeCursor(page.PrevCursor); err != nil {
+			return nil, err
+		}
+	}
+	return missed, nil
+}
+
+func (h *FeedStreamHandler) send(c *gin.Context, post *domain.Post) {
+	// The id is a cursor for resuming; the database keeps microseconds, so
+	// the time is rounded the same way to match the stored post
+	id := domain.NewCursor(post.CreatedAt.Round(time.Microsecond), post.ID, domain.CursorPrev)
+	c.Render(-1, sse.Event{Id: id.Encode(), Event: "post", Data: post})
+	c.Writer.Flush()
+}
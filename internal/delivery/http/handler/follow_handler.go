@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowHandler struct {
+	followUseCase domain.FollowUseCase
+	auth          gin.HandlerFunc
+}
+
+func NewFollowHandler(followUseCase domain.FollowUseCase, auth gin.HandlerFunc) *FollowHandler {
+	return &FollowHandler{
+		followUseCase: followUseCase,
+		auth:          auth,
+	}
+}
+
+func (h *FollowHandler) Register(router *gin.RouterGroup) {
+	users := router.Group("/users")
+	users.Use(h.auth)
+	{
+		users.POST("/:id/follow", h.Follow)
+		users.DELETE("/:id/follow", h.Unfollow)
+		users.GET("/:id/followers", h.GetFollowers)
+		users.GET("/:id/following", h.GetFollowing)
+		users.GET("/me/follow-requests", h.GetFollowRequests)
+		users.POST("/me/follow-requests/:id/approve", h.ApproveFollowRequest)
+		users.DELETE("/me/follow-requests/:id", h.RejectFollowRequest)
+	}
+}
+
+// @Summary Follow user
+// @Description Follow the user with the given ID. Following a private account only asks its owner for approval, answered with 202 and status "requested".
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Success 202 {object} map[string]string
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/{id}/follow [post]
+// @Security Bearer
+func (h *FollowHandler) Follow(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := h.followUseCase.Follow(userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if status == domain.FollowStatusRequested {
+		c.JSON(http.StatusAccepted, gin.H{"status": status})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Unfollow user
+// @Description Stop following the user with the given ID, or withdraw the request to follow them
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/{id}/follow [delete]
+// @Security Bearer
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.followUseCase.Unfollow(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get followers
+// @Description Get paginated list of users following the given user
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Users per page (default: 20)"
+// @Success 200 {array} domain.PublicUser
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/followers [get]
+// @Security Bearer
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.followUseCase.GetFollowers(viewerID, c.Param("id"), page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary Get following
+// @Description Get paginated list of users the given user follows
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Users per page (default: 20)"
+// @Success 200 {array} domain.PublicUser
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/following [get]
+// @Security Bearer
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.followUseCase.GetFollowing(viewerID, c.Param("id"), page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary Get follow requests
+// @Description Get paginated list of users asking to follow the current user, most recent first
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Users per page (default: 20)"
+// @Success 200 {array} domain.PublicUser
+// @Failure 401 {object} map[string]string
+// @Router /users/me/follow-requests [get]
+// @Security Bearer
+func (h *FollowHandler) GetFollowRequests(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.followUseCase.GetFollowRequests(userID, page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary Approve follow request
+// @Description Let the user with the given ID follow the current user
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID of the requester"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/me/follow-requests/{id}/approve [post]
+// @Security Bearer
+func (h *FollowHandler) ApproveFollowRequest(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.followUseCase.ApproveFollowRequest(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Reject follow request
+// @Description Turn down the request of the user with the given ID to follow the current user. They are not told.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "User ID of the requester"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/me/follow-requests/{id} [delete]
+// @Security Bearer
+func (h *FollowHandler) RejectFollowRequest(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.followUseCase.RejectFollowRequest(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HashtagHandler struct {
+	hashtagUseCase domain.HashtagUseCase
+	postUseCase    domain.PostUseCase
+	auth           gin.HandlerFunc
+}
+
+func NewHashtagHandler(hashtagUseCase domain.HashtagUseCase, postUseCase domain.PostUseCase, auth gin.HandlerFunc) *HashtagHandler {
+	return &HashtagHandler{
+		hashtagUseCase: hashtagUseCase,
+		postUseCase:    postUseCase,
+		auth:           auth,
+	}
+}
+
+func (h *HashtagHandler) Register(router *gin.RouterGroup) {
+	hashtags := router.Group("/hashtags")
+	hashtags.Use(h.auth)
+	{
+		hashtags.GET("/trending", h.GetTrending)
+		hashtags.GET("/:tag/posts", h.GetHashtagPosts)
+	}
+}
+
+// @Summary Get trending hashtags
+// @Description Get the hashtags rising fastest over the last day. Recent uses weigh more, and tags used more in the last hour than their daily average rank higher. Trends are recomputed periodically.
+// @Tags hashtags
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param limit query int false "Number of hashtags (default: 10, max: 50)"
+// @Success 200 {array} domain.TrendingHashtag
+// @Failure 401 {object} map[string]string
+// @Router /hashtags/trending [get]
+// @Security Bearer
+func (h *HashtagHandler) GetTrending(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	trends, err := h.hashtagUseCase.GetTrending(limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// @Summary Get hashtag posts
+// @Description Get cursor-paginated posts tagged with a hashtag, newest first. Tags are case-insensitive.
+// @Tags hashtags
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param tag path string true "Hashtag, without the #"
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Posts per page (default: 10, max: 100)"
+// @Success 200 {object} domain.PostPage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /hashtags/{tag}/posts [get]
+// @Security Bearer
+func (h *HashtagHandler) GetHashtagPosts(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.postUseCase.GetHashtagPosts(userID, c.Param("tag"), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
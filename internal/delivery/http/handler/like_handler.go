@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LikeHandler struct {
+	likeUseCase domain.LikeUseCase
+	auth        gin.HandlerFunc
+}
+
+func NewLikeHandler(likeUseCase domain.LikeUseCase, auth gin.HandlerFunc) *LikeHandler {
+	return &LikeHandler{
+		likeUseCase: likeUseCase,
+		auth:        auth,
+	}
+}
+
+func (h *LikeHandler) Register(router *gin.RouterGroup) {
+	posts := router.Group("/posts")
+	posts.Use(h.auth)
+	{
+		posts.POST("/:id/like", h.LikePost)
+		posts.DELETE("/:id/like", h.UnlikePost)
+		posts.GET("/:id/likes", h.GetLikers)
+	}
+}
+
+// @Summary Like post
+// @Description Like the post with the given ID
+// @Tags likes
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/like [post]
+// @Security Bearer
+func (h *LikeHandler) LikePost(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.likeUseCase.LikePost(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Unlike post
+// @Description Remove the current user's like from the post with the given ID
+// @Tags likes
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Success 204 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /posts/{id}/like [delete]
+// @Security Bearer
+func (h *LikeHandler) UnlikePost(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.likeUseCase.UnlikePost(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get post likers
+// @Description Get paginated list of users who liked the post
+// @Tags likes
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Users per page (default: 20)"
+// @Success 200 {array} domain.PublicUser
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/likes [get]
+// @Security Bearer
+func (h *LikeHandler) GetLikers(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.likeUseCase.GetLikers(userID, c.Param("id"), page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
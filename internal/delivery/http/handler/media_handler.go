@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MediaHandler struct {
+	mediaUseCase domain.MediaUseCase
+	auth         gin.HandlerFunc
+}
+
+func NewMediaHandler(mediaUseCase domain.MediaUseCase, auth gin.HandlerFunc) *MediaHandler {
+	return &MediaHandler{
+		mediaUseCase: mediaUseCase,
+		auth:         auth,
+	}
+}
+
+func (h *MediaHandler) Register(router *gin.RouterGroup) {
+	media := router.Group("/media")
+	media.Use(h.auth)
+	{
+		media.POST("", h.Upload)
+	}
+}
+
+// @Summary Upload media
+// @Description Upload an image or video to attach to posts. Use the returned url in a post's media list.
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param file formData file true "File to upload"
+// @Success 201 {object} domain.Media
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /media [post]
+// @Security Bearer
+func (h *MediaHandler) Upload(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Stream the file part to the use case instead of buffering the whole
+	// form to disk
+	file, err := formFilePart(c, "file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	media, err := h.mediaUseCase.Upload(userID, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+func formFilePart(c *gin.Context, name string) (io.ReadCloser, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("missing form file " + name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == name && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MentionHandler struct {
+	postUseCase domain.PostUseCase
+	auth        gin.HandlerFunc
+}
+
+func NewMentionHandler(postUseCase domain.PostUseCase, auth gin.HandlerFunc) *MentionHandler {
+	return &MentionHandler{
+		postUseCase: postUseCase,
+		auth:        auth,
+	}
+}
+
+func (h *MentionHandler) Register(router *gin.RouterGroup) {
+	users := router.Group("/users")
+	users.Use(h.auth)
+	{
+		users.GET("/me/mentions", h.GetMentions)
+	}
+}
+
+// @Summary Get my mentions
+// @Description Get cursor-paginated posts and comments mentioning the current user, most recent first. Comment mentions include the comment and the post it is on.
+// @Tags mentions
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Mentions per page (default: 10, max: 100)"
+// @Success 200 {object} domain.MentionPage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/me/mentions [get]
+// @Security Bearer
+func (h *MentionHandler) GetMentions(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.postUseCase.GetMentions(userID, query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationUseCase domain.NotificationUseCase
+	auth                gin.HandlerFunc
+}
+
+func NewNotificationHandler(notificationUseCase domain.NotificationUseCase, auth gin.HandlerFunc) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUseCase: notificationUseCase,
+		auth:                auth,
+	}
+}
+
+func (h *NotificationHandler) Register(router *gin.RouterGroup) {
+	notifications := router.Group("/notifications")
+	notifications.Use(h.auth)
+	{
+		notifications.GET("", h.GetNotifications)
+		notifications.GET("/unread-count", h.CountUnread)
+		notifications.POST("/read-all", h.MarkAllRead)
+		notifications.POST("/:id/read", h.MarkRead)
+		notifications.GET("/preferences", h.GetPreferences)
+		notifications.PUT("/preferences", h.UpdatePreferences)
+	}
+}
+
+// @Summary Get notifications
+// @Description Get cursor-paginated notifications of the current user, most recent activity first. Likes and comments on the same post, and new followers, are aggregated into one unread notification listing the latest actors and counting all of them.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param unread query bool false "Only unread notifications"
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Notifications per page (default: 10, max: 100)"
+// @Success 200 {object} domain.NotificationPage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /notifications [get]
+// @Security Bearer
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.notificationUseCase.GetNotifications(userID, query, c.Query("unread") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Count unread notifications
+// @Description Get the number of unread notifications of the current user
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} map[string]int
+// @Failure 401 {object} map[string]string
+// @Router /notifications/unread-count [get]
+// @Security Bearer
+func (h *NotificationHandler) CountUnread(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	count, err := h.notificationUseCase.CountUnread(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// @Summary Mark notification read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Notification ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /notifications/{id}/read [post]
+// @Security Bearer
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.notificationUseCase.MarkRead(userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Mark all notifications read
+// @Description Mark every notification of the current user as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /notifications/read-all [post]
+// @Security Bearer
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.notificationUseCase.MarkAllRead(userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get notification preferences
+// @Description Get which notification types the current user receives
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} domain.NotificationPreferences
+// @Failure 401 {object} map[string]string
+// @Router /notifications/preferences [get]
+// @Security Bearer
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	prefs, err := h.notificationUseCase.GetPreferences(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// @Summary Update notification preferences
+// @Description Switch notification types on or off. Types left out keep their setting.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param preferences body domain.NotificationPreferences true "Types to change, e.g. {\"like\": false}"
+// @Success 200 {object} domain.NotificationPreferences
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /notifications/preferences [put]
+// @Security Bearer
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var prefs domain.NotificationPreferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs, err = h.notificationUseCase.UpdatePreferences(userID, prefs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
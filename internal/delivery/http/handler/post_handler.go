@@ -13,52 +13,62 @@ import (
 
 type PostHandler struct {
 	postUseCase domain.PostUseCase
-	secretKey   string
+	auth        gin.HandlerFunc
 }
 
-func NewPostHandler(postUseCase domain.PostUseCase, secretKey string) *PostHandler {
+func NewPostHandler(postUseCase domain.PostUseCase, auth gin.HandlerFunc) *PostHandler {
 	return &PostHandler{
 		postUseCase: postUseCase,
-		secretKey:   secretKey,
+		auth:        auth,
 	}
 }
 
 func (h *PostHandler) Register(router *gin.RouterGroup) {
 	posts := router.Group("/posts")
-	posts.Use(middleware.JWTMiddleware(h.secretKey))
+	posts.Use(h.auth)
 	{
 		posts.POST("/", h.CreatePost)
 		posts.GET("/:id", h.GetPost)
 		posts.PUT("/:id", h.UpdatePost)
+		posts.GET("/:id/revisions", h.GetRevisions)
 		posts.DELETE("/:id", h.DeletePost)
+		posts.POST("/:id/repost", h.Repost)
+		posts.DELETE("/:id/repost", h.Unrepost)
 		posts.GET("/user/:id", h.GetUserPosts)
 		posts.GET("/feed", h.GetFeed)
+		posts.GET("/search", h.SearchPosts)
 	}
 }
 
 // @Summary Create new post
-// @Description Create a new post with the provided content
+// @Description Create a new post with the provided content. Setting quote_of_id quotes that post, with the content as commentary.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer <token>"
-// @Param post body domain.Post true "Post content"
+// @Param post body domain.CreatePostRequest true "Post content"
 // @Success 201 {object} domain.Post
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /posts [post]
 // @Security Bearer
 func (h *PostHandler) CreatePost(c *gin.Context) {
-	var post domain.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
+	var req domain.CreatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
 	// Set post metadata
-	post.ID = uuid.New()
-	post.CreatedAt = time.Now()
-	post.UpdatedAt = time.Now()
+	post := domain.Post{
+		ID:         uuid.New(),
+		Content:    req.Content,
+		Media:      req.Media,
+		Visibility: req.Visibility,
+		QuoteOfID:  req.QuoteOfID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
 
 	// Get user ID from context (assuming it was set by auth middleware)
 	userID, exists := c.Get("user_id")
@@ -76,7 +86,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	post.UserID = parsedUserID
 
 	if err := h.postUseCase.CreatePost(&post); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -98,9 +108,15 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 func (h *PostHandler) GetPost(c *gin.Context) {
 	id := c.Param("id")
 
-	post, err := h.postUseCase.GetPost(id)
+	viewerID, err := middleware.GetUserFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	post, err := h.postUseCase.GetPost(viewerID, id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -108,24 +124,26 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 }
 
 // @Summary Update post
-// @Description Update an existing post
+// @Description Update an existing post. The replaced content and media are kept in the post's revisions. Posts older than the configured edit window cannot be edited.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer <token>"
 // @Param id path string true "Post ID"
-// @Param post body domain.Post true "Updated post content"
+// @Param post body domain.UpdatePostRequest true "Updated post content"
 // @Success 200 {object} domain.Post
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /posts/{id} [put]
 // @Security Bearer
 func (h *PostHandler) UpdatePost(c *gin.Context) {
 	id := c.Param("id")
 
-	var post domain.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
+	var req domain.UpdatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
@@ -136,7 +154,12 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
 		return
 	}
-	post.ID = parsedID
+	post := domain.Post{
+		ID:         parsedID,
+		Content:    req.Content,
+		Media:      req.Media,
+		Visibility: req.Visibility,
+	}
 
 	// Get user ID from context
 	userID, exists := c.Get("user_id")
@@ -155,15 +178,44 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	post.UpdatedAt = time.Now()
 
 	if err := h.postUseCase.UpdatePost(&post); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, post)
 }
 
+// @Summary Get post revisions
+// @Description Get every version of a post, oldest first and ending with the current one, each with how it differs from the version before
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Success 200 {array} domain.PostVersion
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /posts/{id}/revisions [get]
+// @Security Bearer
+func (h *PostHandler) GetRevisions(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	versions, err := h.postUseCase.GetRevisions(viewerID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
 // @Summary Delete post
-// @Description Delete an existing post
+// @Description Delete an existing post. Only the author or a moderator can delete a post.
 // @Tags posts
 // @Accept json
 // @Produce json
@@ -171,14 +223,81 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 // @Param id path string true "Post ID"
 // @Success 204 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /posts/{id} [delete]
 // @Security Bearer
 func (h *PostHandler) DeletePost(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.postUseCase.DeletePost(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	actorID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.postUseCase.DeletePost(actorID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Repost post
+// @Description Share the post with the given ID with the current user's followers. Reposting a repost shares its original. Only the user's own posts and public posts of public accounts can be reposted.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Success 201 {object} domain.Post
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /posts/{id}/repost [post]
+// @Security Bearer
+func (h *PostHandler) Repost(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	repost, err := h.postUseCase.Repost(userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, repost)
+}
+
+// @Summary Undo repost
+// @Description Remove the current user's repost of the post with the given ID
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path string true "Post ID"
+// @Success 204 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /posts/{id}/repost [delete]
+// @Security Bearer
+func (h *PostHandler) Unrepost(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.postUseCase.Unrepost(userID, c.Param("id")); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -186,13 +305,16 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 }
 
 // @Summary Get user posts
-// @Description Get all posts by user ID
+// @Description Get cursor-paginated posts by user ID, newest first
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer <token>"
 // @Param id path string true "User ID"
-// @Success 200 {array} domain.Post
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Posts per page (default: 10, max: 100)"
+// @Success 200 {object} domain.PostPage
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /posts/user/{id} [get]
@@ -200,49 +322,141 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 func (h *PostHandler) GetUserPosts(c *gin.Context) {
 	userID := c.Param("id")
 
-	posts, err := h.postUseCase.GetUserPosts(userID)
+	viewerID, err := middleware.GetUserFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	c.JSON(http.StatusOK, posts)
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.postUseCase.GetUserPosts(viewerID, userID, query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
 }
 
 // @Summary Get post feed
-// @Description Get paginated feed of posts
+// @Description Get cursor-paginated feed of posts from the current user and the accounts they follow
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer <token>"
-// @Param page query int false "Page number (default: 1)"
-// @Param limit query int false "Posts per page (default: 10)"
-// @Success 200 {array} domain.Post
+// @Param cursor query string false "Opaque next_cursor or prev_cursor from a previous page"
+// @Param limit query int false "Posts per page (default: 10, max: 100)"
+// @Success 200 {object} domain.PostPage
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /posts/feed [get]
 // @Security Bearer
 func (h *PostHandler) GetFeed(c *gin.Context) {
-	// Get pagination parameters
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query, err := parsePageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.postUseCase.GetHomeFeed(userID, query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Search posts
+// @Description Full-text search over post content, best matches first with newer posts ranked higher. Quote words to search for a phrase, end a word with * to match prefixes and start one with - to exclude it.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param q query string true "Search query, e.g. \"go conference\" tut* -java"
+// @Param cursor query string false "Opaque next_cursor from a previous page"
+// @Param limit query int false "Posts per page (default: 10, max: 100)"
+// @Success 200 {object} domain.PostPage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /posts/search [get]
+// @Security Bearer
+func (h *PostHandler) SearchPosts(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var cursor *domain.SearchCursor
+	if token := c.Query("cursor"); token != "" {
+		if cursor, err = domain.DecodeSearchCursor(token); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	page, err := h.postUseCase.SearchPosts(userID, c.Query("q"), cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parsePageQuery reads the cursor and limit query parameters. A missing
+// cursor starts from the newest item; the limit is clamped by the use case.
+func parsePageQuery(c *gin.Context) (domain.PageQuery, error) {
+	var query domain.PageQuery
+
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := domain.DecodeCursor(token)
+		if err != nil {
+			return query, err
+		}
+		query.Cursor = cursor
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			query.Limit = parsedLimit
+		}
+	}
+
+	return query, nil
+}
+
+// parsePagination reads the page and limit query parameters, falling back to
+// the first page and defaultLimit when they are missing or invalid.
+func parsePagination(c *gin.Context, defaultLimit int) (int, int) {
 	page := 1
-	limit := 10
-	
+	limit := defaultLimit
+
 	if pageStr := c.Query("page"); pageStr != "" {
 		if parsedPage, err := strconv.Atoi(pageStr); err == nil && parsedPage > 0 {
 			page = parsedPage
 		}
 	}
-	
+
 	if limitStr := c.Query("limit"); limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
 			limit = parsedLimit
 		}
 	}
 
-	posts, err := h.postUseCase.GetFeed(page, limit)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, posts)
+	return page, limit
 }
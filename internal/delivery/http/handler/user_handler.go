@@ -3,51 +3,137 @@ package handler
 import (
 	"net/http"
 	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	userUseCase domain.UserUseCase
+	userUseCase  domain.UserUseCase
+	auth         gin.HandlerFunc
+	optionalAuth gin.HandlerFunc
 }
 
-func NewUserHandler(userUseCase domain.UserUseCase) *UserHandler {
+func NewUserHandler(userUseCase domain.UserUseCase, auth gin.HandlerFunc, optionalAuth gin.HandlerFunc) *UserHandler {
 	return &UserHandler{
-		userUseCase: userUseCase,
+		userUseCase:  userUseCase,
+		auth:         auth,
+		optionalAuth: optionalAuth,
 	}
 }
 
 func (h *UserHandler) Register(router *gin.RouterGroup) {
 	users := router.Group("/users")
 	{
-		users.GET("/:id", h.GetUser)
-		users.POST("/", h.CreateUser)
-		users.PUT("/:id", h.UpdateUser)
-		users.PATCH("/:id", h.PatchUser)
-		users.DELETE("/:id", h.DeleteUser)
+		users.GET("/me", h.auth, h.GetMe)
+		users.GET("/search", h.auth, h.SearchUsers)
+		users.GET("/autocomplete", h.auth, h.AutocompleteUsers)
+		users.GET("/by-username/:username", h.auth, h.GetUserByUsername)
+		users.GET("/:id", h.optionalAuth, h.GetUser)
+		users.POST("/", h.auth, h.CreateUser)
+		users.PUT("/:id", h.auth, h.UpdateUser)
+		users.PATCH("/:id", h.auth, h.PatchUser)
+		users.DELETE("/:id", h.auth, h.DeleteUser)
+		users.PUT("/:id/role", h.auth, h.SetRole)
 	}
 }
 
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
-	user, err := h.userUseCase.GetUser(id)
+
+	// Profiles are public; anonymous viewers get the public view
+	viewerID, _ := middleware.GetUserFromContext(c)
+
+	user, err := h.userUseCase.GetUser(viewerID, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) GetUserByUsername(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	user, err := h.userUseCase.GetUserByUsername(viewerID, c.Param("username"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// SearchUsers fuzzy-matches the q parameter against usernames and full names.
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	page, limit := parsePagination(c, 20)
+
+	users, err := h.userUseCase.SearchUsers(viewerID, c.Query("q"), page, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// AutocompleteUsers suggests usernames starting with the q parameter, for
+// @-mention typeahead.
+func (h *UserHandler) AutocompleteUsers(c *gin.Context) {
+	viewerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	_, limit := parsePagination(c, 10)
+
+	users, err := h.userUseCase.AutocompleteUsers(viewerID, c.Query("q"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	user, err := h.userUseCase.GetUser(userID, userID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
 
 func (h *UserHandler) CreateUser(c *gin.Context) {
-	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	actorID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req domain.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.userUseCase.CreateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	user, err := h.userUseCase.CreateUser(actorID, &req)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -57,22 +143,27 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 
-	// Parse string ID to UUID
-	uid, err := uuid.Parse(id)
+	actorID, err := middleware.GetUserFromContext(c)
 	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Parse string ID to UUID
+	if _, err := uuid.Parse(id); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
 		return
 	}
 
-	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req domain.UserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	user.ID = uid
-	if err := h.userUseCase.UpdateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	user, err := h.userUseCase.UpdateUser(actorID, id, &req)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -82,14 +173,20 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) PatchUser(c *gin.Context) {
 	id := c.Param("id")
 
+	actorID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	var patch domain.UserPatchRequest
 	if err := c.ShouldBindJSON(&patch); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.userUseCase.PatchUser(id, &patch); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.userUseCase.PatchUser(actorID, id, &patch); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -98,9 +195,39 @@ func (h *UserHandler) PatchUser(c *gin.Context) {
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
-	if err := h.userUseCase.DeleteUser(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	actorID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.userUseCase.DeleteUser(actorID, id); err != nil {
+		respondError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+func (h *UserHandler) SetRole(c *gin.Context) {
+	id := c.Param("id")
+
+	actorID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req domain.UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userUseCase.SetRole(actorID, id, req.Role); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated successfully"})
+}
@@ -0,0 +1,117 @@
+// Package realtime delivers pushes to the clients connected to this instance,
+// whatever transport they are connected with.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"socialnetwork/internal/domain"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// resubscribeDelay is how long the hub waits before subscribing to the broker
+// again after losing its subscription.
+const resubscribeDelay = time.Second
+
+// Subscription receives the pushes of one user as encoded PushMessages. The
+// hub closes Messages when the subscriber falls too far behind, so one slow
+// client cannot hold up the others; the subscriber should then disconnect.
+type Subscription struct {
+	UserID   uuid.UUID
+	messages chan []byte
+}
+
+func (s *Subscription) Messages() <-chan []byte {
+	return s.messages
+}
+
+// Hub routes pushes from the broker to the subscriptions of their recipients.
+type Hub struct {
+	broker     domain.PushBroker
+	bufferSize int
+
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]map[*Subscription]struct{}
+}
+
+// NewHub creates a hub buffering up to bufferSize messages per subscription.
+func NewHub(broker domain.PushBroker, bufferSize int) *Hub {
+	return &Hub{
+		broker:        broker,
+		bufferSize:    bufferSize,
+		subscriptions: map[uuid.UUID]map[*Subscription]struct{}{},
+	}
+}
+
+// Run delivers pushes from the broker until ctx is done, resubscribing when
+// the subscription fails.
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		if err := h.broker.Subscribe(ctx, h.deliver); err != nil {
+			log.Printf("realtime: subscription failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	sub := &Subscription{UserID: userID, messages: make(chan []byte, h.bufferSize)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscriptions[userID] == nil {
+		h.subscriptions[userID] = map[*Subscription]struct{}{}
+	}
+	h.subscriptions[userID][sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe stops deliveries to sub. It is safe to call after the hub
+// dropped sub.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// remove drops sub and closes its channel. The caller holds h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subscriptions[sub.UserID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscriptions, sub.UserID)
+	}
+	close(sub.messages)
+}
+
+func (h *Hub) deliver(push *domain.Push) {
+	payload, err := json.Marshal(push.Message)
+	if err != nil {
+		log.Printf("realtime: encoding %s failed: %v", push.Message.Type, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, userID := range push.UserIDs {
+		for sub := range h.subscriptions[userID] {
+			select {
+			case sub.messages <- payload:
+			default:
+				log.Printf("realtime: dropping slow subscriber of user %s", userID)
+				h.remove(sub)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package websocket serves realtime pushes over WebSocket connections.
+package websocket
+
+import (
+	"net/http"
+	"socialnetwork/internal/delivery/realtime"
+	"socialnetwork/internal/middleware"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	gorillaws "github.com/gorilla/websocket"
+)
+
+const (
+	writeWait = 10 * time.Second
+	// Clients must answer pings within pongWait
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// Clients only send control frames, so anything larger is misbehaving
+	maxMessageSize = 512
+	// How often open connections check that their token was not revoked
+	revocationCheckPeriod = 30 * time.Second
+)
+
+type Handler struct {
+	hub        *realtime.Hub
+	secretKey  string
+	revocation middleware.RevocationChecker
+	upgrader   gorillaws.Upgrader
+}
+
+func NewHandler(hub *realtime.Hub, secretKey string, revocation middleware.RevocationChecker) *Handler {
+	return &Handler{
+		hub:        hub,
+		secretKey:  secretKey,
+		revocation: revocation,
+		upgrader: gorillaws.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			// Connections authenticate with a token rather than cookies, so
+			// other origins gain nothing by connecting
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (h *Handler) Register(router gin.IRoutes) {
+	router.GET("/ws", h.Serve)
+}
+
+// Serve upgrades the request to a WebSocket connection pushing the user's
+// feed items and notifications. Browsers cannot set headers on WebSocket
+// requests, so the access token may also be passed as the token query
+// parameter. The connection is closed when the token expires or is revoked.
+func (h *Handler) Serve(c *gin.Context) {
+	claims, err := h.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied
+		return
+	}
+
+	sub := h.hub.Subscribe(userID)
+	go h.write(conn, sub, claims)
+	h.read(conn, sub)
+}
+
+func (h *Handler) authenticate(c *gin.Context) (*middleware.Claims, error) {
+	token := c.Query("token")
+	if header := c.GetHeader("Authorization"); header != "" {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	if token == "" {
+		return nil, middleware.ErrNoToken
+	}
+
+	claims, err := middleware.ValidateToken(token, h.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	if h.revocation != nil {
+		revoked, err := h.revocation.IsTokenRevoked(claims)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, middleware.ErrInvalidToken
+		}
+	}
+	return claims, nil
+}
+
+// read consumes control frames until the connection fails or the client stops
+// answering pings, then tears the connection down.
+func (h *Handler) read(conn *gorillaws.Conn, sub *realtime.Subscription) {
+	defer func() {
+		h.hub.Unsubscribe(sub)
+		conn.Close()
+	}()
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// write sends pushes and pings. It closes the connection when the hub drops
+// the subscription for falling behind, and when the token expires or is
+// revoked.
+func (h *Handler) write(conn *gorillaws.Conn, sub *realtime.Subscription, claims *middleware.Claims) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	var expired <-chan time.Time
+	if claims.ExpiresAt != nil {
+		timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var recheck <-chan time.Time
+	if h.revocation != nil {
+		revocationTicker := time.NewTicker(revocationCheckPeriod)
+		defer revocationTicker.Stop()
+		recheck = revocationTicker.C
+	}
+
+	for {
+		select {
+		case message, ok := <-sub.Messages():
+			if !ok {
+				closeWith(conn, gorillaws.CloseTryAgainLater, "too slow")
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(gorillaws.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(gorillaws.PingMessage, nil); err != nil {
+				return
+			}
+		case <-expired:
+			closeWith(conn, gorillaws.ClosePolicyViolation, "token expired")
+			return
+		case <-recheck:
+			// Keep the connection when the check itself fails, and try again
+			// on the next tick
+			if revoked, err := h.revocation.IsTokenRevoked(claims); err == nil && revoked {
+				closeWith(conn, gorillaws.ClosePolicyViolation, "token revoked")
+				return
+			}
+		}
+	}
+}
+
+func closeWith(conn *gorillaws.Conn, code int, reason string) {
+	message := gorillaws.FormatCloseMessage(code, reason)
+	conn.WriteControl(gorillaws.CloseMessage, message, time.Now().Add(writeWait))
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Block hides two users from each other and stops them interacting. Only the
+// blocker can lift it.
+type Block struct {
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"type:uuid;primaryKey"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Mute hides the muted user's posts from the muter's feeds, and nothing
+// else. The muted user is not told.
+type Mute struct {
+	MuterID   uuid.UUID `json:"muter_id" gorm:"type:uuid;primaryKey"`
+	MutedID   uuid.UUID `json:"muted_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlockRepository interface {
+	// Create records the block and ends any follow, or follow request,
+	// between the two users
+	Create(block *Block) error
+	Delete(blockerID string, blockedID string) error
+	// IsBlocked reports whether either user blocked the other
+	IsBlocked(a uuid.UUID, b uuid.UUID) (bool, error)
+	GetBlocked(userID string, page int, limit int) ([]*User, error)
+}
+
+type MuteRepository interface {
+	Create(mute *Mute) error
+	Delete(muterID string, mutedID string) error
+	GetMuted(userID string, page int, limit int) ([]*User, error)
+	GetMuterIDs(userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type BlockUseCase interface {
+	Block(blockerID string, blockedID string) error
+	Unblock(blockerID string, blockedID string) error
+	GetBlocked(userID string, page int, limit int) ([]*PublicUser, error)
+	Mute(muterID string, mutedID string) error
+	Unmute(muterID string, mutedID string) error
+	GetMuted(userID string, page int, limit int) ([]*PublicUser, error)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCommentLength bounds comment content, in bytes, as for posts.
+const MaxCommentLength = MaxPostLength
+
+type Comment struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	PostID    uuid.UUID  `json:"post_id" gorm:"type:uuid"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid"`
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	Replies  []*Comment       `json:"replies,omitempty" gorm:"-"`
+	Mentions []*MentionEntity `json:"mentions" gorm:"-"`
+}
+
+type CommentPage struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	PrevCursor string     `json:"prev_cursor,omitempty"`
+}
+
+type CommentRepository interface {
+	GetByID(id string) (*Comment, error)
+	Create(comment *Comment) error
+	Update(comment *Comment) error
+	Delete(id string) error
+	GetRootsByPostID(viewerID string, postID string, query PageQuery) ([]*Comment, error)
+	GetDescendants(viewerID string, rootIDs []uuid.UUID) ([]*Comment, error)
+	GetByIDs(ids []uuid.UUID) ([]*Comment, error)
+}
+
+type CommentUseCase interface {
+	GetComments(viewerID string, postID string, query PageQuery) (*CommentPage, error)
+	CreateComment(comment *Comment) error
+	UpdateComment(comment *Comment) error
+	DeleteComment(userID string, commentID string) error
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxConversationParticipants bounds group conversations, including the
+	// user who starts them.
+	MaxConversationParticipants = 10
+	// MaxMessageLength bounds message content, in bytes.
+	MaxMessageLength = 4000
+)
+
+// Conversation is a private thread between two or more users. DirectKey is
+// set on one-to-one conversations so that the same two users always share
+// a single thread.
+type Conversation struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Title         string    `json:"title,omitempty"`
+	DirectKey     *string   `json:"-"`
+	CreatedBy     uuid.UUID `json:"created_by" gorm:"type:uuid"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at"`
+
+	// Computed for the requesting user, not persisted
+	Participants []*PublicUser `json:"participants" gorm:"-"`
+	LastMessage  *Message      `json:"last_message,omitempty" gorm:"-"`
+	UnreadCount  int64         `json:"unread_count" gorm:"-"`
+}
+
+type ConversationParticipant struct {
+	ConversationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	JoinedAt       time.Time
+	LastReadAt     *time.Time
+}
+
+type Message struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	ConversationID uuid.UUID `json:"conversation_id" gorm:"type:uuid"`
+	SenderID       uuid.UUID `json:"sender_id" gorm:"type:uuid"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type MessagePage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	PrevCursor string     `json:"prev_cursor,omitempty"`
+}
+
+type StartConversationRequest struct {
+	// The other participants; the requesting user is added
+	ParticipantIDs []string `json:"participant_ids" binding:"required,min=1"`
+	Title          string   `json:"title"`
+}
+
+type SendMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+type ConversationRepository interface {
+	GetByID(id string) (*Conversation, error)
+	GetByDirectKey(key string) (*Conversation, error)
+	// Create stores conversation together with its participants.
+	Create(conversation *Conversation, participantIDs []uuid.UUID) error
+	// GetByUserID lists the user's conversations, latest activity first.
+	GetByUserID(userID string, page int, limit int) ([]*Conversation, error)
+	IsParticipant(conversationID uuid.UUID, userID uuid.UUID) (bool, error)
+	GetParticipantIDs(conversationID uuid.UUID) ([]uuid.UUID, error)
+	GetParticipants(conversationIDs []uuid.UUID) (map[uuid.UUID][]*User, error)
+	// MarkRead records that the user has read everything up to at.
+	MarkRead(conversationID uuid.UUID, userID uuid.UUID, at time.Time) error
+	// CountUnread counts, for each of the user's conversations, the messages
+	// of others sent after the user last read it.
+	CountUnread(userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	CountAllUnread(userID uuid.UUID) (int64, error)
+}
+
+type MessageRepository interface {
+	// Create stores message and moves its conversation's last activity.
+	Create(message *Message) error
+	GetByConversationID(conversationID uuid.UUID, query PageQuery) ([]*Message, error)
+	GetLatest(conversationIDs []uuid.UUID) (map[uuid.UUID]*Message, error)
+}
+
+type ConversationUseCase interface {
+	StartConversation(userID string, req *StartConversationRequest) (*Conversation, error)
+	GetConversation(userID string, id string) (*Conversation, error)
+	GetConversations(userID string, page int, limit int) ([]*Conversation, error)
+	GetMessages(userID string, conversationID string, query PageQuery) (*MessagePage, error)
+	SendMessage(userID string, conversationID string, req *SendMessageRequest) (*Message, error)
+	MarkRead(userID string, conversationID string) error
+	CountUnread(userID string) (int64, error)
+}
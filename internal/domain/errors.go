@@ -0,0 +1,43 @@
+package domain
+
+import "errors"
+
+// Error kinds. Every error returned by a use case that the client can act on
+// wraps one of these, so the delivery layer can pick a status code with
+// errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrForbidden  = errors.New("forbidden")
+	ErrValidation = errors.New("validation failed")
+	ErrConflict   = errors.New("conflict")
+)
+
+// Error is a domain error with a message that is safe to show to clients.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func NewNotFoundError(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func NewForbiddenError(message string) error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func NewValidationError(message string) error {
+	return &Error{Kind: ErrValidation, Message: message}
+}
+
+func NewConflictError(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventPostCreated    EventType = "post.created"
+	EventUserFollowed   EventType = "user.followed"
+	EventPostLiked      EventType = "post.liked"
+	EventCommentCreated EventType = "comment.created"
+	EventUserMentioned  EventType = "user.mentioned"
+	EventMessageCreated EventType = "message.created"
+	// EventPostReposted and EventPostQuoted tell UserID that ActorID shared
+	// their post: PostID is the reposted post, or the quote
+	EventPostReposted EventType = "post.reposted"
+	EventPostQuoted   EventType = "post.quoted"
+	// EventFollowRequested is sent when ActorID asks to follow the private
+	// account of UserID
+	EventFollowRequested EventType = "user.follow_requested"
+	// EventNotificationCreated follows the other events once a notification
+	// for UserID has been recorded or updated
+	EventNotificationCreated EventType = "notification.created"
+)
+
+// Event describes something a user did. Fields that do not apply to the type
+// are left zero.
+type Event struct {
+	Type    EventType
+	ActorID uuid.UUID
+	// UserID is the user acted on: the followed or mentioned user, or the
+	// author of the liked or commented post
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CommentID uuid.UUID
+	// Post is the created post, for post.created
+	Post *Post
+	// Notification is the recorded notification, for notification.created
+	Notification *Notification
+	// Message is the sent message, for message.created
+	Message   *Message
+	CreatedAt time.Time
+}
+
+// EventPublisher hands events to whoever reacts to them. Publishing does not
+// block and never fails the action that caused the event.
+type EventPublisher interface {
+	Publish(event Event)
+}
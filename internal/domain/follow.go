@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id" gorm:"type:uuid;primaryKey"`
+	FolloweeID uuid.UUID `json:"followee_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FollowRequest is a follow of a private account waiting for its owner's
+// approval.
+type FollowRequest struct {
+	FollowerID uuid.UUID `json:"follower_id" gorm:"type:uuid;primaryKey"`
+	FolloweeID uuid.UUID `json:"followee_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FollowStatus tells whether a follow took effect or awaits approval.
+type FollowStatus string
+
+const (
+	FollowStatusFollowing FollowStatus = "following"
+	FollowStatusRequested FollowStatus = "requested"
+)
+
+type FollowRepository interface {
+	// Create reports whether the follow is new; following twice is a no-op
+	Create(follow *Follow) (bool, error)
+	Delete(followerID string, followeeID string) error
+	// GetFollowers and GetFollowing leave out users who blocked, or were
+	// blocked by, the viewer
+	GetFollowers(viewerID string, userID string, page int, limit int) ([]*User, error)
+	GetFollowing(viewerID string, userID string, page int, limit int) ([]*User, error)
+	GetFollowerIDs(userID uuid.UUID) ([]uuid.UUID, error)
+	IsFollowing(followerID uuid.UUID, followeeID uuid.UUID) (bool, error)
+	// CreateRequest reports whether the request is new; asking twice keeps
+	// the first request
+	CreateRequest(request *FollowRequest) (bool, error)
+	DeleteRequest(followerID string, followeeID string) error
+	GetRequests(userID string, page int, limit int) ([]*User, error)
+	// ApproveRequest turns the request into a follow
+	ApproveRequest(followerID uuid.UUID, followeeID uuid.UUID) error
+}
+
+type FollowUseCase interface {
+	Follow(followerID string, followeeID string) (FollowStatus, error)
+	Unfollow(followerID string, followeeID string) error
+	GetFollowRequests(userID string, page int, limit int) ([]*PublicUser, error)
+	ApproveFollowRequest(userID string, followerID string) error
+	RejectFollowRequest(userID string, followerID string) error
+	GetFollowers(viewerID string, userID string, page int, limit int) ([]*PublicUser, error)
+	GetFollowing(viewerID string, userID string, page int, limit int) ([]*PublicUser, error)
+}
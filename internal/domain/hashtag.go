@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const maxHashtagLength = 100
+
+// hashtagPattern matches #tag when the # does not follow a word character,
+// so URLs with fragments and "C#" are left alone.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])#([\p{L}\p{N}_]+)`)
+
+type Hashtag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PostHashtag links a post to a hashtag it mentions. CreatedAt is the time the
+// post was written, which the trending windows are measured against.
+type PostHashtag struct {
+	PostID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	HashtagID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time
+}
+
+// TrendingHashtag is a hashtag's activity as of the last trend computation.
+type TrendingHashtag struct {
+	Tag        string    `json:"tag"`
+	Uses1h     int64     `json:"uses_1h" gorm:"column:uses_1h"`
+	Uses24h    int64     `json:"uses_24h" gorm:"column:uses_24h"`
+	Score      float64   `json:"score"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// NormalizeHashtag lowercases tag and drops a leading #.
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+// ParseHashtags returns the distinct normalized hashtags in content, in order
+// of first appearance. Tags need at least one letter, so "#1" is not a tag.
+func ParseHashtags(content string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(content, -1) {
+		tag := NormalizeHashtag(match[1])
+		if len(tag) > maxHashtagLength || !strings.ContainsFunc(tag, unicode.IsLetter) || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+type HashtagRepository interface {
+	// SetPostHashtags replaces the hashtags linked to a post.
+	SetPostHashtags(post *Post, tags []string) error
+	// RefreshTrending recomputes the trending table as of now.
+	RefreshTrending(now time.Time) error
+	GetTrending(limit int) ([]*TrendingHashtag, error)
+}
+
+type HashtagUseCase interface {
+	GetTrending(limit int) ([]*TrendingHashtag, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PostLike struct {
+	PostID    uuid.UUID `json:"post_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LikeRepository interface {
+	// Create reports whether the like is new; liking a post twice is a no-op
+	Create(like *PostLike) (bool, error)
+	Delete(postID string, userID string) error
+	// GetLikers leaves out users who blocked, or were blocked by, the viewer
+	GetLikers(viewerID string, postID string, page int, limit int) ([]*User, error)
+	CountByPostIDs(postIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	GetLikedPostIDs(userID string, postIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+type LikeUseCase interface {
+	LikePost(userID string, postID string) error
+	UnlikePost(userID string, postID string) error
+	// GetLikers lists who liked the post, which must be visible to the viewer
+	GetLikers(viewerID string, postID string, page int, limit int) ([]*PublicUser, error)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MediaStatus string
+
+const (
+	// MediaProcessing images are served in their original size only until
+	// their variants have been generated.
+	MediaProcessing MediaStatus = "processing"
+	MediaReady      MediaStatus = "ready"
+	MediaFailed     MediaStatus = "failed"
+)
+
+// Media is a file uploaded by a user for use in posts and avatars. Identical
+// content is stored once and shared between the users who uploaded it.
+type Media struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primary_key"`
+	UserID       uuid.UUID   `json:"user_id" gorm:"type:uuid"`
+	Hash         string      `json:"hash"`
+	ContentType  string      `json:"content_type"`
+	Size         int64       `json:"size"`
+	StorageKey   string      `json:"-"`
+	URL          string      `json:"url"`
+	Status       MediaStatus `json:"status" gorm:"default:ready"`
+	ThumbnailURL string      `json:"thumbnail_url,omitempty"`
+	MediumURL    string      `json:"medium_url,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+func (Media) TableName() string {
+	return "media"
+}
+
+// ImageVariants are the sizes an uploaded image can be requested in.
+type ImageVariants struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Original  string `json:"original"`
+}
+
+// Variants returns the URLs to request the media in. Sizes that have not been
+// generated, or never will be (videos, GIFs), fall back to the original.
+func (m *Media) Variants() *ImageVariants {
+	variants := &ImageVariants{Thumbnail: m.URL, Medium: m.URL, Original: m.URL}
+	if m.ThumbnailURL != "" {
+		variants.Thumbnail = m.ThumbnailURL
+	}
+	if m.MediumURL != "" {
+		variants.Medium = m.MediumURL
+	}
+	return variants
+}
+
+// MediaStorage stores uploaded files under a key and serves them by URL.
+type MediaStorage interface {
+	Put(key string, body []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	URL(key string) string
+}
+
+type MediaRepository interface {
+	GetByID(id string) (*Media, error)
+	Create(media *Media) error
+	UpdateVariants(media *Media) error
+	GetByHash(hash string) ([]*Media, error)
+	GetByURLs(urls []string) ([]*Media, error)
+	GetOwnedURLs(userID string, urls []string) (map[string]bool, error)
+	GetStaleProcessing(before time.Time) ([]*Media, error)
+}
+
+type MediaUseCase interface {
+	Upload(userID string, file io.Reader) (*Media, error)
+}
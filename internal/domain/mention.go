@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// mentionPattern matches @username when the @ does not follow a word
+// character, so e-mail addresses are left alone. Dots and hyphens are only
+// part of a username between other characters, so "@alice." ends a sentence.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_]+(?:[.\-][\p{L}\p{N}_]+)*)`)
+
+// Mention records that a post, or a comment on it when CommentID is set,
+// mentions a user.
+type Mention struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	AuthorID  uuid.UUID  `json:"author_id" gorm:"type:uuid"`
+	PostID    uuid.UUID  `json:"post_id" gorm:"type:uuid"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Where the mention was made
+	Post    *Post    `json:"post,omitempty" gorm:"-"`
+	Comment *Comment `json:"comment,omitempty" gorm:"-"`
+}
+
+type MentionPage struct {
+	Mentions   []*Mention `json:"mentions"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	PrevCursor string     `json:"prev_cursor,omitempty"`
+}
+
+// MentionEntity locates a mention of a user in content. Start and End are
+// offsets in Unicode code points, End exclusive, and cover the leading @.
+type MentionEntity struct {
+	Start    int       `json:"start"`
+	End      int       `json:"end"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+// mentionToken is an @username found in content.
+type mentionToken struct {
+	username   string
+	start, end int
+}
+
+func findMentions(content string) []mentionToken {
+	var tokens []mentionToken
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(content, -1) {
+		// loc[2:4] is the username; the @ is the byte before it
+		start := utf8.RuneCountInString(content[:loc[2]-1])
+		tokens = append(tokens, mentionToken{
+			username: content[loc[2]:loc[3]],
+			start:    start,
+			end:      start + 1 + utf8.RuneCountInString(content[loc[2]:loc[3]]),
+		})
+	}
+	return tokens
+}
+
+// ParseMentions returns the distinct usernames mentioned in content, in order
+// of first appearance. Usernames are compared ignoring case.
+func ParseMentions(content string) []string {
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, token := range findMentions(content) {
+		key := strings.ToLower(token.username)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, token.username)
+	}
+	return usernames
+}
+
+// MentionEntities locates the mentions of users in content. Mentions of
+// anyone else are plain text.
+func MentionEntities(content string, users []*User) []*MentionEntity {
+	byUsername := make(map[string]*User, len(users))
+	for _, user := range users {
+		byUsername[strings.ToLower(user.Username)] = user
+	}
+
+	entities := []*MentionEntity{}
+	for _, token := range findMentions(content) {
+		user, ok := byUsername[strings.ToLower(token.username)]
+		if !ok {
+			continue
+		}
+		entities = append(entities, &MentionEntity{
+			Start:    token.start,
+			End:      token.end,
+			UserID:   user.ID,
+			Username: user.Username,
+		})
+	}
+	return entities
+}
+
+type MentionRepository interface {
+	// SetPostMentions replaces the users mentioned by the body of a post and
+	// returns those who were not mentioned before.
+	SetPostMentions(post *Post, userIDs []uuid.UUID) ([]uuid.UUID, error)
+	// SetCommentMentions replaces the users mentioned by a comment and
+	// returns those who were not mentioned before.
+	SetCommentMentions(comment *Comment, userIDs []uuid.UUID) ([]uuid.UUID, error)
+	// GetPostMentions returns the users mentioned by each post's body.
+	GetPostMentions(postIDs []uuid.UUID) (map[uuid.UUID][]*User, error)
+	// GetCommentMentions returns the users mentioned by each comment.
+	GetCommentMentions(commentIDs []uuid.UUID) (map[uuid.UUID][]*User, error)
+	// GetByUserID pages through the mentions of a user by others in posts
+	// and comments that still exist.
+	GetByUserID(userID string, query PageQuery) ([]*Mention, error)
+}
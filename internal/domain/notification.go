@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationPost    NotificationType = "post"
+	NotificationFollow  NotificationType = "follow"
+	NotificationLike    NotificationType = "like"
+	NotificationComment NotificationType = "comment"
+	NotificationMention NotificationType = "mention"
+	// NotificationFollowRequest asks the owner of a private account to approve
+	// a follower
+	NotificationFollowRequest NotificationType = "follow_request"
+	NotificationRepost        NotificationType = "repost"
+	NotificationQuote         NotificationType = "quote"
+)
+
+// NotificationTypes lists every notification type users can switch off.
+var NotificationTypes = []NotificationType{
+	NotificationPost,
+	NotificationFollow,
+	NotificationFollowRequest,
+	NotificationLike,
+	NotificationComment,
+	NotificationMention,
+	NotificationRepost,
+	NotificationQuote,
+}
+
+func (t NotificationType) IsValid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells a user that others interacted with them. Unread
+// notifications with the same GroupKey aggregate: every further actor is
+// added to the same notification ("X and 4 others liked your post"), and
+// CreatedAt moves to the latest activity.
+type Notification struct {
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID        `json:"user_id" gorm:"type:uuid"`
+	Type       NotificationType `json:"type"`
+	GroupKey   string           `json:"-"`
+	ActorID    uuid.UUID        `json:"actor_id" gorm:"type:uuid"`
+	ActorCount int              `json:"actor_count"`
+	PostID     *uuid.UUID       `json:"post_id,omitempty" gorm:"type:uuid"`
+	CommentID  *uuid.UUID       `json:"comment_id,omitempty" gorm:"type:uuid"`
+	ReadAt     *time.Time       `json:"read_at,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+
+	// The most recent actors, latest first
+	Actors []*PublicUser `json:"actors" gorm:"-"`
+}
+
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+	PrevCursor    string          `json:"prev_cursor,omitempty"`
+}
+
+// NotificationPreferences maps each notification type to whether the user
+// receives it.
+type NotificationPreferences map[NotificationType]bool
+
+type NotificationRepository interface {
+	// Add notifies n.UserID of n.ActorID's activity, aggregating it into the
+	// user's unread notification with the same group key when there is one.
+	Add(n *Notification) error
+	// AddForFollowers notifies every follower of n.ActorID who has not
+	// switched off n.Type.
+	AddForFollowers(n *Notification) error
+	GetByUserID(userID string, query PageQuery, unreadOnly bool) ([]*Notification, error)
+	// GetActors returns up to limit of the latest actors of each notification.
+	GetActors(notificationIDs []uuid.UUID, limit int) (map[uuid.UUID][]*User, error)
+	CountUnread(userID string) (int64, error)
+	MarkRead(userID string, id string) error
+	MarkAllRead(userID string) error
+	GetDisabledTypes(userID string) ([]NotificationType, error)
+	SetPreference(userID string, notificationType NotificationType, enabled bool) error
+}
+
+type NotificationUseCase interface {
+	GetNotifications(userID string, query PageQuery, unreadOnly bool) (*NotificationPage, error)
+	CountUnread(userID string) (int64, error)
+	MarkRead(userID string, id string) error
+	MarkAllRead(userID string) error
+	GetPreferences(userID string) (NotificationPreferences, error)
+	UpdatePreferences(userID string, prefs NotificationPreferences) (NotificationPreferences, error)
+}
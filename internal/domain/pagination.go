@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type CursorDirection string
+
+const (
+	// CursorNext walks towards older items
+	CursorNext CursorDirection = "next"
+	// CursorPrev walks towards newer items
+	CursorPrev CursorDirection = "prev"
+)
+
+// Cursor marks a position in a list ordered by (created_at, id) descending.
+type Cursor struct {
+	CreatedAt time.Time       `json:"t"`
+	ID        uuid.UUID       `json:"id"`
+	Direction CursorDirection `json:"d"`
+}
+
+func NewCursor(createdAt time.Time, id uuid.UUID, direction CursorDirection) *Cursor {
+	return &Cursor{CreatedAt: createdAt, ID: id, Direction: direction}
+}
+
+// Encode returns the opaque token handed out to clients.
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Direction != CursorNext && cursor.Direction != CursorPrev {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+type PageQuery struct {
+	Cursor *Cursor
+	Limit  int
+}
+
+// Backward reports whether the query walks towards newer items.
+func (q PageQuery) Backward() bool {
+	return q.Cursor != nil && q.Cursor.Direction == CursorPrev
+}
+
+type PostPage struct {
+	Posts      []*Post `json:"posts"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	PrevCursor string  `json:"prev_cursor,omitempty"`
+}
@@ -6,30 +6,135 @@ import (
 	"github.com/google/uuid"
 )
 
+// MaxPostLength bounds post content, in bytes.
+const MaxPostLength = 4000
+
+// Visibility decides who besides the author can see a post. Users the post
+// mentions can see it unless it is private.
+type Visibility string
+
+const (
+	// VisibilityPublic posts can be seen by everyone, or only by followers
+	// when the author's account is private
+	VisibilityPublic    Visibility = "public"
+	VisibilityFollowers Visibility = "followers"
+	VisibilityMentioned Visibility = "mentioned"
+	VisibilityPrivate   Visibility = "private"
+)
+
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityFollowers, VisibilityMentioned, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
 type Post struct {
-	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
-	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid"`
-	Content   string     `json:"content"`
-	Media     []string   `json:"media" gorm:"type:text[]"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	Content    string     `json:"content"`
+	Media      []string   `json:"media" gorm:"type:text[]"`
+	Visibility Visibility `json:"visibility"`
+	// A repost shares RepostOfID as is, with no content of its own; a quote
+	// shares QuoteOfID with the post's content as commentary
+	RepostOfID *uuid.UUID `json:"repost_of_id,omitempty" gorm:"type:uuid"`
+	QuoteOfID  *uuid.UUID `json:"quote_of_id,omitempty" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+
+	// Computed for the requesting user, not persisted
+	LikeCount    int64 `json:"like_count" gorm:"-"`
+	LikedByMe    bool  `json:"liked_by_me" gorm:"-"`
+	RepostCount  int64 `json:"repost_count" gorm:"-"`
+	RepostedByMe bool  `json:"reposted_by_me" gorm:"-"`
+	// The reposted or quoted post, left out once it is deleted or when the
+	// requesting user may not see it
+	RepostOf *Post `json:"repost_of,omitempty" gorm:"-"`
+	QuoteOf  *Post `json:"quote_of,omitempty" gorm:"-"`
+
+	// Sized versions of each entry of Media, in the same order
+	MediaVariants []*ImageVariants `json:"media_variants" gorm:"-"`
+	// Parsed from Content
+	Hashtags []string         `json:"hashtags" gorm:"-"`
+	Mentions []*MentionEntity `json:"mentions" gorm:"-"`
+}
+
+// CreatePostRequest is what clients may set on a new post; the rest is
+// filled in by the server.
+type CreatePostRequest struct {
+	Content    string     `json:"content" binding:"required"`
+	Media      []string   `json:"media"`
+	Visibility Visibility `json:"visibility"`
+	QuoteOfID  *uuid.UUID `json:"quote_of_id,omitempty"`
 }
 
+// UpdatePostRequest is what clients may change on an existing post; the
+// visibility is kept unless given.
+type UpdatePostRequest struct {
+	Content    string     `json:"content"`
+	Media      []string   `json:"media"`
+	Visibility Visibility `json:"visibility"`
+}
+
+// PostRepository reads leave out the posts viewerID may not see: those of
+// users who blocked, or were blocked by, the viewer, those whose visibility
+// excludes them and reposts of such posts. An empty viewerID is for internal
+// reads. The home feed also leaves out users the viewer muted, and shows each
+// post once however many times it was reposted.
 type PostRepository interface {
-	GetByID(id string) (*Post, error)
-	GetByUserID(userID string) ([]*Post, error)
+	GetByID(viewerID string, id string) (*Post, error)
+	IsVisible(viewerID string, id uuid.UUID) (bool, error)
+	GetByUserID(viewerID string, userID string, query PageQuery) ([]*Post, error)
 	Create(post *Post) error
+	// Update keeps the content and media it replaces as a revision and marks
+	// the post edited, unless only the visibility changed
 	Update(post *Post) error
+	// GetRevisions lists the revisions of the post, oldest first
+	GetRevisions(postID uuid.UUID) ([]*PostRevision, error)
+	// Delete removes the post along with its reposts; quotes of it stay
 	Delete(id string) error
-	GetFeed(page int, limit int) ([]*Post, error)
+	// GetRepost returns the user's repost of the post
+	GetRepost(userID string, postID string) (*Post, error)
+	// GetReposts lists the reposts of the post, which go along with it
+	GetReposts(postID string) ([]*Post, error)
+	// DeleteRepost removes the user's repost of the post, and fails with a
+	// not found error when there is none
+	DeleteRepost(userID string, postID string) error
+	CountReposts(postIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	GetRepostedPostIDs(userID string, postIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	GetHomeFeed(userID string, query PageQuery) ([]*Post, error)
+	Search(query PostSearchQuery) ([]*PostSearchHit, error)
+	GetByHashtag(viewerID string, tag string, query PageQuery) ([]*Post, error)
+	GetByIDs(viewerID string, ids []uuid.UUID) ([]*Post, error)
+}
+
+// FeedCache drops cached feed pages when what their viewers may see changes
+// other than through a post being written.
+type FeedCache interface {
+	// InvalidateViewers drops the feeds cached for the given users
+	InvalidateViewers(userIDs ...string)
+	// InvalidateAll drops every cached feed, for changes that may show or
+	// hide posts to anyone, such as an account going private
+	InvalidateAll()
 }
 
 type PostUseCase interface {
-	GetPost(id string) (*Post, error)
-	GetUserPosts(userID string) ([]*Post, error)
+	GetPost(viewerID string, id string) (*Post, error)
+	GetUserPosts(viewerID string, userID string, query PageQuery) (*PostPage, error)
 	CreatePost(post *Post) error
 	UpdatePost(post *Post) error
-	DeletePost(id string) error
-	GetFeed(page int, limit int) ([]*Post, error)
+	// GetRevisions lists the versions of the post, oldest first
+	GetRevisions(viewerID string, id string) ([]*PostVersion, error)
+	DeletePost(actorID string, id string) error
+	// Repost shares the post with the user's followers and returns the repost
+	Repost(userID string, postID string) (*Post, error)
+	Unrepost(userID string, postID string) error
+	GetHomeFeed(userID string, query PageQuery) (*PostPage, error)
+	SearchPosts(viewerID string, text string, cursor *SearchCursor, limit int) (*PostPage, error)
+	GetHashtagPosts(viewerID string, tag string, query PageQuery) (*PostPage, error)
+	// GetMentions pages through the posts and comments mentioning the user.
+	GetMentions(userID string, query PageQuery) (*MentionPage, error)
 }
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type PushType string
+
+const (
+	// PushFeedPost carries a new post for the recipient's home feed
+	PushFeedPost PushType = "feed.post"
+	// PushNotification carries a new or updated notification
+	PushNotification PushType = "notification"
+	// PushDirectMessage carries a new direct message
+	PushDirectMessage PushType = "message"
+)
+
+// PushMessage is what realtime clients receive.
+type PushMessage struct {
+	Type PushType        `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func NewPushMessage(pushType PushType, data interface{}) (PushMessage, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return PushMessage{}, err
+	}
+	return PushMessage{Type: pushType, Data: raw}, nil
+}
+
+// Push addresses a message to the connected clients of some users.
+type Push struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+	Message PushMessage `json:"message"`
+}
+
+// PushBroker shares pushes between every API instance, so a push reaches the
+// user wherever they are connected.
+type PushBroker interface {
+	Publish(push *Push) error
+	// Subscribe calls deliver with every push published by any instance until
+	// ctx is done.
+	Subscribe(ctx context.Context, deliver func(push *Push)) error
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// PostRevision is a version of a post that an edit replaced. CreatedAt is when
+// that version was written, not when it was replaced.
+type PostRevision struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	PostID    uuid.UUID `json:"post_id" gorm:"type:uuid"`
+	Content   string    `json:"content"`
+	Media     []string  `json:"media" gorm:"type:text[]"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DiffOpType string
+
+const (
+	DiffEqual  DiffOpType = "equal"
+	DiffInsert DiffOpType = "insert"
+	DiffDelete DiffOpType = "delete"
+)
+
+// DiffOp is a run of text an edit kept, added or removed.
+type DiffOp struct {
+	Op   DiffOpType `json:"op"`
+	Text string     `json:"text"`
+}
+
+// PostVersion is one version in a post's edit history, with what changed
+// since the version before it. The first version lists no changes.
+type PostVersion struct {
+	Content      string    `json:"content"`
+	Media        []string  `json:"media"`
+	CreatedAt    time.Time `json:"created_at"`
+	Diff         []DiffOp  `json:"diff,omitempty"`
+	MediaAdded   []string  `json:"media_added,omitempty"`
+	MediaRemoved []string  `json:"media_removed,omitempty"`
+}
+
+// NewPostVersions lists the versions of post, oldest first, from the
+// revisions its edits replaced followed by the current one.
+func NewPostVersions(revisions []*PostRevision, post *Post) []*PostVersion {
+	versions := make([]*PostVersion, 0, len(revisions)+1)
+	for _, revision := range revisions {
+		versions = append(versions, &PostVersion{
+			Content:   revision.Content,
+			Media:     revision.Media,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+	current := &PostVersion{Content: post.Content, Media: post.Media, CreatedAt: post.CreatedAt}
+	if post.EditedAt != nil {
+		current.CreatedAt = *post.EditedAt
+	}
+	versions = append(versions, current)
+
+	for i := 1; i < len(versions); i++ {
+		prev, version := versions[i-1], versions[i]
+		version.Diff = DiffText(prev.Content, version.Content)
+		for _, url := range version.Media {
+			if !slices.Contains(prev.Media, url) {
+				version.MediaAdded = append(version.MediaAdded, url)
+			}
+		}
+		for _, url := range prev.Media {
+			if !slices.Contains(version.Media, url) {
+				version.MediaRemoved = append(version.MediaRemoved, url)
+			}
+		}
+	}
+	return versions
+}
+
+// maxDiffCells bounds the table DiffText fills, counted as the words of one
+// text times the words of the other, so long texts stay cheap to compare.
+const maxDiffCells = 1 << 20
+
+// DiffText compares two texts word by word. The equal and delete runs make up
+// from, the equal and insert runs make up to. The words both texts start and
+// end with are kept as they are; when what lies between is too long to
+// compare, it is reported as deleted and inserted as a whole.
+func DiffText(from string, to string) []DiffOp {
+	a, b := splitWords(from), splitWords(to)
+
+	var ops []DiffOp
+	add := func(op DiffOpType, text string) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text
+			return
+		}
+		ops = append(ops, DiffOp{Op: op, Text: text})
+	}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, word := range a[:prefix] {
+		add(DiffEqual, word)
+	}
+	diffWords(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], add)
+	for _, word := range a[len(a)-suffix:] {
+		add(DiffEqual, word)
+	}
+	return ops
+}
+
+// diffWords reports a run of words a and b share as equal, and the rest as
+// deleted from a or inserted from b, through add.
+func diffWords(a []string, b []string, add func(op DiffOpType, text string)) {
+	if len(a)*len(b) > maxDiffCells {
+		for _, word := range a {
+			add(DiffDelete, word)
+		}
+		for _, word := range b {
+			add(DiffInsert, word)
+		}
+		return
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffDelete, a[i])
+			i++
+		default:
+			add(DiffInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(DiffDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(DiffInsert, b[j])
+	}
+}
+
+// splitWords cuts s into alternating runs of whitespace and other characters,
+// so that joining them gives s back.
+func splitWords(s string) []string {
+	var words []string
+	start, space := 0, false
+	for i, r := range s {
+		isSpace := unicode.IsSpace(r)
+		if i > start && isSpace != space {
+			words = append(words, s[start:i])
+			start = i
+		}
+		space = isSpace
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
@@ -0,0 +1,41 @@
+package domain
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	// PermManageUsers allows creating, editing and deleting other accounts
+	PermManageUsers Permission = "users:manage"
+	// PermManageRoles allows changing the role of any account
+	PermManageRoles Permission = "roles:manage"
+	// PermModeratePosts allows removing other users' posts
+	PermModeratePosts Permission = "posts:moderate"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser:      {},
+	RoleModerator: {PermModeratePosts},
+	RoleAdmin:     {PermManageUsers, PermManageRoles, PermModeratePosts},
+}
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the given permission.
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
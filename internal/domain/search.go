@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// MaxSearchQueryLength bounds the length of search text, in bytes.
+const MaxSearchQueryLength = 256
+
+// SearchTerm is one element of a parsed search query: a single word, or a
+// phrase whose words must appear next to each other in order.
+type SearchTerm struct {
+	Words []string
+	// Prefix matches any word starting with the last word
+	Prefix bool
+	// Exclude drops results containing the term
+	Exclude bool
+}
+
+// ParseSearchQuery splits text into terms. "Quoted text" is a phrase, a
+// trailing * makes a prefix query and a leading - excludes the term. Words
+// are reduced to letters and digits; punctuation inside a word (e-mail)
+// joins its parts into a phrase. At least one term must not be excluded.
+func ParseSearchQuery(text string) ([]SearchTerm, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, NewValidationError("search query is required")
+	}
+	if len(text) > MaxSearchQueryLength {
+		return nil, NewValidationError("search query is too long")
+	}
+
+	var terms []SearchTerm
+	positive := false
+	for len(text) > 0 {
+		exclude := false
+		if text[0] == '-' {
+			exclude = true
+			text = text[1:]
+		}
+
+		var raw string
+		if strings.HasPrefix(text, `"`) {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				// Unterminated quotes run to the end of the query
+				raw, text = text[1:], ""
+			} else {
+				raw, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(text, unicode.IsSpace)
+			if end < 0 {
+				end = len(text)
+			}
+			raw, text = text[:end], text[end:]
+		}
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+
+		prefix := strings.HasSuffix(raw, "*")
+		words := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+
+		terms = append(terms, SearchTerm{Words: words, Prefix: prefix, Exclude: exclude})
+		positive = positive || !exclude
+	}
+
+	if !positive {
+		return nil, NewValidationError("search query must contain at least one word to match")
+	}
+	return terms, nil
+}
+
+// SearchCursor marks a position in search results ordered by (score, id)
+// descending.
+type SearchCursor struct {
+	Score float64   `json:"s"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque token handed out to clients.
+func (c *SearchCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeSearchCursor(token string) (*SearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor SearchCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID == uuid.Nil || math.IsNaN(cursor.Score) || math.IsInf(cursor.Score, 0) {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+type PostSearchQuery struct {
+	Terms    []SearchTerm
+	Cursor   *SearchCursor
+	Limit    int
+	ViewerID string
+}
+
+// PostSearchHit is a search result with the score it is ordered by.
+type PostSearchHit struct {
+	Post  *Post
+	Score float64
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+// RefreshToken is the server-side record behind an opaque refresh token.
+// Every token rotated out of the same login shares its SessionID.
+type RefreshToken struct {
+	UserID    string
+	SessionID string
+	IssuedAt  time.Time
+}
+
+type TokenRepository interface {
+	SaveRefreshToken(token string, refreshToken *RefreshToken, ttl time.Duration) error
+	// ConsumeRefreshToken marks the token as used and reports whether it had
+	// already been used before.
+	ConsumeRefreshToken(token string) (*RefreshToken, bool, error)
+	RevokeSession(sessionID string, ttl time.Duration) error
+	IsSessionRevoked(sessionID string) (bool, error)
+	// RevokeUserTokens invalidates every token of the user issued before the
+	// given time.
+	RevokeUserTokens(userID string, before time.Time, ttl time.Duration) error
+	GetUserRevokedBefore(userID string) (time.Time, error)
+}
@@ -10,33 +10,70 @@ type User struct {
 	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
 	Username  string     `json:"username" gorm:"uniqueIndex"`
 	Email     string     `json:"email" gorm:"uniqueIndex"`
-	Password  string     `json:"password"`
+	Password  string     `json:"-"`
 	FullName  string     `json:"full_name"`
 	Bio       string     `json:"bio"`
 	Avatar    string     `json:"avatar"`
+	Role      Role       `json:"role" gorm:"default:user"`
+	IsPrivate bool       `json:"is_private"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Resolved from the uploaded Avatar, not persisted
+	AvatarVariants *ImageVariants `json:"-" gorm:"-"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	FullName string `json:"full_name"`
+	Role     Role   `json:"role"`
+}
+
+type UserUpdateRequest struct {
+	FullName string `json:"full_name"`
+	Bio      string `json:"bio"`
+	Avatar   string `json:"avatar"`
+	// IsPrivate is left unchanged when omitted, so that clients unaware of
+	// it cannot make a private account public by accident
+	IsPrivate *bool `json:"is_private,omitempty"`
 }
 
 type UserPatchRequest struct {
-	FullName *string `json:"full_name,omitempty"`
-	Bio      *string `json:"bio,omitempty"`
-	Avatar   *string `json:"avatar,omitempty"`
+	FullName  *string `json:"full_name,omitempty"`
+	Bio       *string `json:"bio,omitempty"`
+	Avatar    *string `json:"avatar,omitempty"`
+	IsPrivate *bool   `json:"is_private,omitempty"`
+}
+
+type UserRoleRequest struct {
+	Role Role `json:"role" binding:"required"`
 }
 
 type UserRepository interface {
 	GetByID(id string) (*User, error)
 	GetByEmail(email string) (*User, error)
+	GetByUsername(username string) (*User, error)
+	GetByUsernames(usernames []string) ([]*User, error)
+	// Search and SearchByPrefix leave out users who blocked, or were blocked
+	// by, the viewer
+	Search(viewerID string, query string, page int, limit int) ([]*User, error)
+	SearchByPrefix(viewerID string, prefix string, limit int) ([]*User, error)
 	Create(user *User) error
 	Update(user *User) error
 	Delete(id string) error
 }
 
 type UserUseCase interface {
-	GetUser(id string) (*User, error)
-	CreateUser(user *User) error
-	UpdateUser(user *User) error
-	PatchUser(id string, patch *UserPatchRequest) error
-	DeleteUser(id string) error
+	GetUser(viewerID string, id string) (UserView, error)
+	GetUserByUsername(viewerID string, username string) (UserView, error)
+	SearchUsers(viewerID string, query string, page int, limit int) ([]*PublicUser, error)
+	AutocompleteUsers(viewerID string, prefix string, limit int) ([]*PublicUser, error)
+	CreateUser(actorID string, req *CreateUserRequest) (*AdminUser, error)
+	UpdateUser(actorID string, id string, req *UserUpdateRequest) (UserView, error)
+	PatchUser(actorID string, id string, patch *UserPatchRequest) error
+	DeleteUser(actorID string, id string) error
+	SetRole(actorID string, id string, role Role) error
 }
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserView is one of the projections of a User that may be sent to clients.
+// The persistence struct itself is never serialized.
+type UserView interface {
+	userView()
+}
+
+// PublicUser is the profile anyone can see.
+type PublicUser struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Bio       string    `json:"bio"`
+	Avatar    string    `json:"avatar"`
+	IsPrivate bool      `json:"is_private"`
+	CreatedAt time.Time `json:"created_at"`
+
+	AvatarVariants *ImageVariants `json:"avatar_variants,omitempty"`
+}
+
+// SelfUser is the profile shown to the account owner.
+type SelfUser struct {
+	PublicUser
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AdminUser is the profile shown to administrators.
+type AdminUser struct {
+	SelfUser
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (*PublicUser) userView() {}
+func (*SelfUser) userView()   {}
+func (*AdminUser) userView()  {}
+
+func NewPublicUser(user *User) *PublicUser {
+	return &PublicUser{
+		ID:        user.ID,
+		Username:  user.Username,
+		FullName:  user.FullName,
+		Bio:       user.Bio,
+		Avatar:    user.Avatar,
+		IsPrivate: user.IsPrivate,
+		CreatedAt: user.CreatedAt,
+
+		AvatarVariants: user.AvatarVariants,
+	}
+}
+
+func NewPublicUsers(users []*User) []*PublicUser {
+	views := make([]*PublicUser, len(users))
+	for i, user := range users {
+		views[i] = NewPublicUser(user)
+	}
+	return views
+}
+
+func NewSelfUser(user *User) *SelfUser {
+	return &SelfUser{
+		PublicUser: *NewPublicUser(user),
+		Email:      user.Email,
+		Role:       user.Role,
+		UpdatedAt:  user.UpdatedAt,
+	}
+}
+
+func NewAdminUser(user *User) *AdminUser {
+	return &AdminUser{
+		SelfUser:  *NewSelfUser(user),
+		DeletedAt: user.DeletedAt,
+	}
+}
+
+// NewUserView picks the projection of user that viewer is allowed to see.
+// A nil viewer gets the public profile.
+func NewUserView(user *User, viewer *User) UserView {
+	switch {
+	case viewer != nil && viewer.Role.Can(PermManageUsers):
+		return NewAdminUser(user)
+	case viewer != nil && viewer.ID == user.ID:
+		return NewSelfUser(user)
+	default:
+		return NewPublicUser(user)
+	}
+}
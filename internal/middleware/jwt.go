@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 var (
@@ -16,16 +17,26 @@ var (
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID string, secretKey string) (string, error) {
+// RevocationChecker reports whether an otherwise valid token has been revoked
+// server-side, e.g. by a logout.
+type RevocationChecker interface {
+	IsTokenRevoked(claims *Claims) (bool, error)
+}
+
+func GenerateToken(userID string, sessionID string, secretKey string, ttl time.Duration) (string, error) {
+	now := time.Now()
 	claims := Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -49,7 +60,7 @@ func ValidateToken(tokenString string, secretKey string) (*Claims, error) {
 	return nil, ErrInvalidToken
 }
 
-func JWTMiddleware(secretKey string) gin.HandlerFunc {
+func JWTMiddleware(secretKey string, revocation RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -73,8 +84,54 @@ func JWTMiddleware(secretKey string) gin.HandlerFunc {
 			return
 		}
 
-		// Set user ID in context
+		if revocation != nil {
+			revoked, err := revocation.IsTokenRevoked(claims)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate token"})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
+		// Set user and session IDs in context
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
+		c.Next()
+	}
+}
+
+// OptionalJWTMiddleware lets requests without a token through anonymously,
+// and checks those that carry one as JWTMiddleware does.
+func OptionalJWTMiddleware(secretKey string, revocation RevocationChecker) gin.HandlerFunc {
+	required := JWTMiddleware(secretKey, revocation)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
+// TokenFromQuery lets clients that cannot set headers, such as browser
+// EventSource, pass the access token as the token query parameter. It goes in
+// front of JWTMiddleware on the routes that need it, and takes the token off
+// the URL so that nothing after it sees or records it.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get("token"); token != "" {
+			if c.GetHeader("Authorization") == "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+			query.Del("token")
+			c.Request.URL.RawQuery = query.Encode()
+		}
 		c.Next()
 	}
 }
@@ -86,3 +143,11 @@ func GetUserFromContext(c *gin.Context) (string, error) {
 	}
 	return userID.(string), nil
 }
+
+func GetSessionFromContext(c *gin.Context) (string, error) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return "", errors.New("session not found in context")
+	}
+	return sessionID.(string), nil
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) domain.BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Create(block *domain.Block) error {
+	block.CreatedAt = time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Blocking someone twice is a no-op rather than an error
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error; err != nil {
+			return err
+		}
+		between := "(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)"
+		args := []interface{}{block.BlockerID, block.BlockedID, block.BlockedID, block.BlockerID}
+		if err := tx.Where(between, args...).Delete(&domain.Follow{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(between, args...).Delete(&domain.FollowRequest{}).Error
+	})
+}
+
+func (r *blockRepository) Delete(blockerID string, blockedID string) error {
+	blockerUID, err := parseID(blockerID, "user")
+	if err != nil {
+		return err
+	}
+	blockedUID, err := parseID(blockedID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Where("blocker_id = ? AND blocked_id = ?", blockerUID, blockedUID).
+		Delete(&domain.Block{}).Error
+}
+
+func (r *blockRepository) IsBlocked(a uuid.UUID, b uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) GetBlocked(userID string, page int, limit int) ([]*domain.User, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := r.db.Joins("JOIN blocks ON blocks.blocked_id = users.id").
+		Where("blocks.blocker_id = ?", uid).
+		Order("blocks.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// excludeBlocked hides the rows of tx whose userColumn is a user who blocked,
+// or was blocked by, the viewer. Anonymous viewers, and internal reads passing
+// no viewer, see every row.
+func excludeBlocked(tx *gorm.DB, userColumn string, viewerID string) (*gorm.DB, error) {
+	if viewerID == "" {
+		return tx, nil
+	}
+	condition, err := notBlocked(userColumn, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Where(condition), nil
+}
+
+// notBlocked is the condition behind excludeBlocked, for raw queries. With no
+// viewer it holds for every row.
+func notBlocked(userColumn string, viewerID string) (clause.Expr, error) {
+	if viewerID == "" {
+		return clause.Expr{SQL: "TRUE"}, nil
+	}
+	uid, err := parseID(viewerID, "user")
+	if err != nil {
+		return clause.Expr{}, err
+	}
+	return clause.Expr{
+		SQL: "NOT EXISTS (SELECT 1 FROM blocks WHERE " +
+			"(blocks.blocker_id = ? AND blocks.blocked_id = " + userColumn + ") OR " +
+			"(blocks.blocker_id = " + userColumn + " AND blocks.blocked_id = ?))",
+		Vars: []interface{}{uid, uid},
+	}, nil
+}
+
+// excludeMuted hides the rows of tx whose userColumn is a user the viewer
+// muted.
+func excludeMuted(tx *gorm.DB, userColumn string, viewerID string) (*gorm.DB, error) {
+	if viewerID == "" {
+		return tx, nil
+	}
+	uid, err := parseID(viewerID, "user")
+	if err != nil {
+		return nil, err
+	}
+	return tx.Where("NOT EXISTS (SELECT 1 FROM mutes WHERE mutes.muter_id = ? AND mutes.muted_id = "+userColumn+")", uid), nil
+}
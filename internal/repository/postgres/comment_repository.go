@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) domain.CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) GetByID(id string) (*domain.Comment, error) {
+	var comment domain.Comment
+	uid, err := parseID(id, "comment")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Where("id = ? AND deleted_at IS NULL", uid).First(&comment).Error; err != nil {
+		return nil, translateError(err, "comment not found")
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) Create(comment *domain.Comment) error {
+	comment.ID = uuid.New()
+	comment.CreatedAt = time.Now()
+	comment.UpdatedAt = time.Now()
+	return r.db.Create(comment).Error
+}
+
+func (r *commentRepository) Update(comment *domain.Comment) error {
+	comment.UpdatedAt = time.Now()
+	return r.db.Model(comment).Where("id = ? AND deleted_at IS NULL", comment.ID).
+		Updates(map[string]interface{}{
+			"content":    comment.Content,
+			"updated_at": comment.UpdatedAt,
+		}).Error
+}
+
+// Delete soft-deletes the comment so that its replies stay attached to the
+// thread, and drops the mentions it made along with it.
+func (r *commentRepository) Delete(id string) error {
+	uid, err := parseID(id, "comment")
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Comment{}).
+			Where("id = ? AND deleted_at IS NULL", uid).
+			Update("deleted_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Where("comment_id = ?", uid).Delete(&domain.Mention{}).Error
+	})
+}
+
+// GetRootsByPostID pages through the top-level comments of a post, including
+// deleted ones which may still carry replies. Comments by users who blocked,
+// or were blocked by, the viewer are left out.
+func (r *commentRepository) GetRootsByPostID(viewerID string, postID string, query domain.PageQuery) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+	uid, err := parseID(postID, "post")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := excludeBlocked(r.db.Where("post_id = ? AND parent_id IS NULL", uid), "comments.user_id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginate(tx, query, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetDescendants loads every reply below the given comments, oldest first.
+// Replies by users who blocked, or were blocked by, the viewer are left out
+// along with everything below them.
+func (r *commentRepository) GetDescendants(viewerID string, rootIDs []uuid.UUID) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+	if len(rootIDs) == 0 {
+		return comments, nil
+	}
+
+	visible, err := notBlocked("c.user_id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT c.* FROM comments c WHERE c.parent_id IN ? AND ?
+			UNION ALL
+			SELECT c.* FROM comments c JOIN tree t ON c.parent_id = t.id WHERE ?
+		)
+		SELECT * FROM tree ORDER BY created_at ASC, id ASC`, rootIDs, visible, visible).
+		Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+	if len(ids) == 0 {
+		return comments, nil
+	}
+
+	if err := r.db.Where("id IN ? AND deleted_at IS NULL", ids).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
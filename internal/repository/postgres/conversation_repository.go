@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type conversationRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationRepository(db *gorm.DB) domain.ConversationRepository {
+	return &conversationRepository{db: db}
+}
+
+func (r *conversationRepository) GetByID(id string) (*domain.Conversation, error) {
+	uid, err := parseID(id, "conversation")
+	if err != nil {
+		return nil, err
+	}
+
+	var conversation domain.Conversation
+	if err := r.db.Where("id = ?", uid).First(&conversation).Error; err != nil {
+		return nil, translateError(err, "conversation not found")
+	}
+	return &conversation, nil
+}
+
+func (r *conversationRepository) GetByDirectKey(key string) (*domain.Conversation, error) {
+	var conversation domain.Conversation
+	if err := r.db.Where("direct_key = ?", key).First(&conversation).Error; err != nil {
+		return nil, translateError(err, "conversation not found")
+	}
+	return &conversation, nil
+}
+
+func (r *conversationRepository) Create(conversation *domain.Conversation, participantIDs []uuid.UUID) error {
+	conversation.ID = uuid.New()
+	conversation.CreatedAt = time.Now()
+	conversation.LastMessageAt = conversation.CreatedAt
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return translateError(err, "")
+		}
+
+		participants := make([]*domain.ConversationParticipant, len(participantIDs))
+		for i, userID := range participantIDs {
+			participants[i] = &domain.ConversationParticipant{
+				ConversationID: conversation.ID,
+				UserID:         userID,
+				JoinedAt:       conversation.CreatedAt,
+			}
+		}
+		return tx.Create(&participants).Error
+	})
+}
+
+func (r *conversationRepository) GetByUserID(userID string, page int, limit int) ([]*domain.Conversation, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var conversations []*domain.Conversation
+	mine := r.db.Model(&domain.ConversationParticipant{}).Select("conversation_id").Where("user_id = ?", uid)
+	err = r.db.Where("id IN (?)", mine).
+		Order("last_message_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+func (r *conversationRepository) IsParticipant(conversationID uuid.UUID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *conversationRepository) GetParticipantIDs(conversationID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.ConversationParticipant{}).
+		Where("conversation_id = ?", conversationID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *conversationRepository) GetParticipants(conversationIDs []uuid.UUID) (map[uuid.UUID][]*domain.User, error) {
+	participants := make(map[uuid.UUID][]*domain.User, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return participants, nil
+	}
+
+	var rows []struct {
+		domain.User
+		ConversationID uuid.UUID
+	}
+	err := r.db.Table("conversation_participants cp").
+		Select("users.*, cp.conversation_id").
+		Joins("JOIN users ON users.id = cp.user_id").
+		Where("cp.conversation_id IN ?", conversationIDs).
+		Order("cp.joined_at, users.username").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		participants[rows[i].ConversationID] = append(participants[rows[i].ConversationID], &rows[i].User)
+	}
+	return participants, nil
+}
+
+// MarkRead never moves the read position backwards.
+func (r *conversationRepository) MarkRead(conversationID uuid.UUID, userID uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Where("last_read_at IS NULL OR last_read_at < ?", at).
+		Update("last_read_at", at).Error
+}
+
+// unreadMessages joins each of the user's participations to the messages of
+// others they have not read yet.
+func (r *conversationRepository) unreadMessages(userID uuid.UUID) *gorm.DB {
+	return r.db.Table("conversation_participants cp").
+		Joins("JOIN messages m ON m.conversation_id = cp.conversation_id AND m.sender_id <> cp.user_id").
+		Where("cp.user_id = ?", userID).
+		Where("cp.last_read_at IS NULL OR m.created_at > cp.last_read_at")
+}
+
+func (r *conversationRepository) CountUnread(userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ConversationID uuid.UUID
+		Count          int64
+	}
+	err := r.unreadMessages(userID).
+		Select("cp.conversation_id, COUNT(*) AS count").
+		Where("cp.conversation_id IN ?", conversationIDs).
+		Group("cp.conversation_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *conversationRepository) CountAllUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.unreadMessages(userID).Count(&count).Error
+	return count, err
+}
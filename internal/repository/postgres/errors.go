@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"errors"
+	"socialnetwork/internal/domain"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func parseID(id string, name string) (uuid.UUID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, domain.NewValidationError("invalid " + name + " id")
+	}
+	return uid, nil
+}
+
+// translateError turns the gorm errors callers can act on into domain errors.
+func translateError(err error, notFoundMessage string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.NewNotFoundError(notFoundMessage)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.NewConflictError("resource already exists")
+	default:
+		return err
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike quotes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) domain.FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) Create(follow *domain.Follow) (bool, error) {
+	follow.CreatedAt = time.Now()
+	// Following someone twice is a no-op rather than an error
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *followRepository) Delete(followerID string, followeeID string) error {
+	followerUID, err := parseID(followerID, "user")
+	if err != nil {
+		return err
+	}
+	followeeUID, err := parseID(followeeID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Where("follower_id = ? AND followee_id = ?", followerUID, followeeUID).
+		Delete(&domain.Follow{}).Error
+}
+
+func (r *followRepository) GetFollowers(viewerID string, userID string, page int, limit int) ([]*domain.User, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := excludeBlocked(r.db, "users.id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := tx.Joins("JOIN follows ON follows.follower_id = users.id").
+		Where("follows.followee_id = ?", uid).
+		Order("follows.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *followRepository) GetFollowing(viewerID string, userID string, page int, limit int) ([]*domain.User, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := excludeBlocked(r.db, "users.id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := tx.Joins("JOIN follows ON follows.followee_id = users.id").
+		Where("follows.follower_id = ?", uid).
+		Order("follows.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *followRepository) GetFollowerIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&domain.Follow{}).Where("followee_id = ?", userID).Pluck("follower_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *followRepository) IsFollowing(followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Follow{}).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepository) CreateRequest(request *domain.FollowRequest) (bool, error) {
+	request.CreatedAt = time.Now()
+	// Asking twice keeps the first request
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(request)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *followRepository) DeleteRequest(followerID string, followeeID string) error {
+	followerUID, err := parseID(followerID, "user")
+	if err != nil {
+		return err
+	}
+	followeeUID, err := parseID(followeeID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Where("follower_id = ? AND followee_id = ?", followerUID, followeeUID).
+		Delete(&domain.FollowRequest{}).Error
+}
+
+func (r *followRepository) GetRequests(userID string, page int, limit int) ([]*domain.User, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := r.db.Joins("JOIN follow_requests ON follow_requests.follower_id = users.id").
+		Where("follow_requests.followee_id = ?", uid).
+		Order("follow_requests.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *followRepository) ApproveRequest(followerID uuid.UUID, followeeID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+			Delete(&domain.FollowRequest{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewNotFoundError("follow request not found")
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.Follow{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+			CreatedAt:  time.Now(),
+		}).Error
+	})
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// trendingHalfLife is how long it takes a use to lose half its weight.
+	trendingHalfLife = 2 * time.Hour
+	// trendingMinUses keeps one-off tags off the trending list.
+	trendingMinUses = 2
+	// trendingLockID serializes refreshes across instances.
+	trendingLockID = 7210332
+)
+
+// refreshTrendingSQL scores every tag used in the last 24 hours. Uses decay
+// exponentially with age, and the sum is boosted by how far the last hour
+// runs ahead of the tag's hourly average over the day, so rising tags
+// outrank tags that are merely always busy. Trends are shown to everyone, so
+// only public posts of public accounts count.
+const refreshTrendingSQL = `
+INSERT INTO hashtag_trends (hashtag_id, uses_1h, uses_24h, score, computed_at)
+SELECT ph.hashtag_id,
+	COUNT(*) FILTER (WHERE ph.created_at > @now::timestamptz - interval '1 hour'),
+	COUNT(*),
+	SUM(power(0.5, EXTRACT(EPOCH FROM (@now::timestamptz - ph.created_at))::float8 / @half_life))
+		* (COUNT(*) FILTER (WHERE ph.created_at > @now::timestamptz - interval '1 hour') + 1)
+		/ (COUNT(*) / 24.0 + 1),
+	@now
+FROM post_hashtags ph
+JOIN posts p ON p.id = ph.post_id AND p.deleted_at IS NULL AND p.visibility = 'public'
+JOIN users u ON u.id = p.user_id AND u.deleted_at IS NULL AND NOT u.is_private
+WHERE ph.created_at > @now::timestamptz - interval '24 hours' AND ph.created_at <= @now
+GROUP BY ph.hashtag_id
+HAVING COUNT(*) >= @min_uses`
+
+type hashtagRepository struct {
+	db *gorm.DB
+}
+
+func NewHashtagRepository(db *gorm.DB) domain.HashtagRepository {
+	return &hashtagRepository{db: db}
+}
+
+// SetPostHashtags links post to exactly tags, creating hashtags seen for the
+// first time. Links keep the time the post was created, so editing a post
+// does not make its tags trend again.
+func (r *hashtagRepository) SetPostHashtags(post *domain.Post, tags []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if len(tags) > 0 {
+			hashtags := make([]*domain.Hashtag, len(tags))
+			for i, tag := range tags {
+				hashtags[i] = &domain.Hashtag{ID: uuid.New(), Tag: tag, CreatedAt: time.Now()}
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&hashtags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.Hashtag{}).Where("tag IN ?", tags).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+		}
+
+		unlink := tx.Where("post_id = ?", post.ID)
+		if len(ids) > 0 {
+			unlink = unlink.Where("hashtag_id NOT IN ?", ids)
+		}
+		if err := unlink.Delete(&domain.PostHashtag{}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		links := make([]*domain.PostHashtag, len(ids))
+		for i, id := range ids {
+			links[i] = &domain.PostHashtag{PostID: post.ID, HashtagID: id, CreatedAt: post.CreatedAt}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+	})
+}
+
+// RefreshTrending replaces the trending table with scores as of now.
+func (r *hashtagRepository) RefreshTrending(now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", trendingLockID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM hashtag_trends").Error; err != nil {
+			return err
+		}
+		return tx.Exec(refreshTrendingSQL, map[string]interface{}{
+			"now":       now,
+			"half_life": trendingHalfLife.Seconds(),
+			"min_uses":  trendingMinUses,
+		}).Error
+	})
+}
+
+func (r *hashtagRepository) GetTrending(limit int) ([]*domain.TrendingHashtag, error) {
+	var trends []*domain.TrendingHashtag
+	err := r.db.Table("hashtag_trends t").
+		Select("h.tag, t.uses_1h, t.uses_24h, t.score, t.computed_at").
+		Joins("JOIN hashtags h ON h.id = t.hashtag_id").
+		Order("t.score DESC, h.tag").
+		Limit(limit).
+		Scan(&trends).Error
+	if err != nil {
+		return nil, err
+	}
+	return trends, nil
+}
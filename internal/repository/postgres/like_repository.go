@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type likeRepository struct {
+	db *gorm.DB
+}
+
+func NewLikeRepository(db *gorm.DB) domain.LikeRepository {
+	return &likeRepository{db: db}
+}
+
+func (r *likeRepository) Create(like *domain.PostLike) (bool, error) {
+	like.CreatedAt = time.Now()
+	// Liking a post twice is a no-op rather than an error
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(like)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *likeRepository) Delete(postID string, userID string) error {
+	postUID, err := parseID(postID, "post")
+	if err != nil {
+		return err
+	}
+	userUID, err := parseID(userID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Where("post_id = ? AND user_id = ?", postUID, userUID).
+		Delete(&domain.PostLike{}).Error
+}
+
+func (r *likeRepository) GetLikers(viewerID string, postID string, page int, limit int) ([]*domain.User, error) {
+	uid, err := parseID(postID, "post")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := excludeBlocked(r.db, "post_likes.user_id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := tx.Joins("JOIN post_likes ON post_likes.user_id = users.id").
+		Where("post_likes.post_id = ?", uid).
+		Order("post_likes.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *likeRepository) CountByPostIDs(postIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PostID uuid.UUID
+		Count  int64
+	}
+	if err := r.db.Model(&domain.PostLike{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.PostID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *likeRepository) GetLikedPostIDs(userID string, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	liked := make(map[uuid.UUID]bool, len(postIDs))
+	if len(postIDs) == 0 {
+		return liked, nil
+	}
+
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	if err := r.db.Model(&domain.PostLike{}).
+		Where("user_id = ? AND post_id IN ?", uid, postIDs).
+		Pluck("post_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		liked[id] = true
+	}
+	return liked, nil
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) domain.MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (r *mediaRepository) GetByID(id string) (*domain.Media, error) {
+	uid, err := parseID(id, "media")
+	if err != nil {
+		return nil, err
+	}
+
+	var media domain.Media
+	if err := r.db.Where("id = ?", uid).First(&media).Error; err != nil {
+		return nil, translateError(err, "media not found")
+	}
+	return &media, nil
+}
+
+func (r *mediaRepository) Create(media *domain.Media) error {
+	media.ID = uuid.New()
+	media.CreatedAt = time.Now()
+	return translateError(r.db.Create(media).Error, "")
+}
+
+// UpdateVariants stores the processing outcome of media on every upload of
+// the same content, since they share the stored files.
+func (r *mediaRepository) UpdateVariants(media *domain.Media) error {
+	return r.db.Model(&domain.Media{}).
+		Where("hash = ?", media.Hash).
+		Updates(map[string]interface{}{
+			"status":        media.Status,
+			"thumbnail_url": media.ThumbnailURL,
+			"medium_url":    media.MediumURL,
+		}).Error
+}
+
+func (r *mediaRepository) GetByHash(hash string) ([]*domain.Media, error) {
+	var media []*domain.Media
+	if err := r.db.Where("hash = ?", hash).Order("created_at").Find(&media).Error; err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (r *mediaRepository) GetByURLs(urls []string) ([]*domain.Media, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	var media []*domain.Media
+	if err := r.db.Where("url IN ?", urls).Find(&media).Error; err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (r *mediaRepository) GetOwnedURLs(userID string, urls []string) (map[string]bool, error) {
+	owned := make(map[string]bool, len(urls))
+	if len(urls) == 0 {
+		return owned, nil
+	}
+
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	if err := r.db.Model(&domain.Media{}).
+		Where("user_id = ? AND url IN ?", uid, urls).
+		Pluck("url", &found).Error; err != nil {
+		return nil, err
+	}
+
+	for _, url := range found {
+		owned[url] = true
+	}
+	return owned, nil
+}
+
+func (r *mediaRepository) GetStaleProcessing(before time.Time) ([]*domain.Media, error) {
+	var media []*domain.Media
+	if err := r.db.Where("status = ? AND created_at < ?", domain.MediaProcessing, before).
+		Order("created_at").
+		Find(&media).Error; err != nil {
+		return nil, err
+	}
+	return media, nil
+}
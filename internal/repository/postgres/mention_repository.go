@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"slices"
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) domain.MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+func (r *mentionRepository) SetPostMentions(post *domain.Post, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	source := r.db.Where("post_id = ? AND comment_id IS NULL", post.ID)
+	return r.setMentions(source, userIDs, func(userID uuid.UUID) *domain.Mention {
+		return &domain.Mention{UserID: userID, AuthorID: post.UserID, PostID: post.ID}
+	})
+}
+
+func (r *mentionRepository) SetCommentMentions(comment *domain.Comment, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	source := r.db.Where("comment_id = ?", comment.ID)
+	return r.setMentions(source, userIDs, func(userID uuid.UUID) *domain.Mention {
+		commentID := comment.ID
+		return &domain.Mention{UserID: userID, AuthorID: comment.UserID, PostID: comment.PostID, CommentID: &commentID}
+	})
+}
+
+// setMentions makes the mentions matching source exactly userIDs and returns
+// the users added. Mentions that remain keep their id and time, so editing
+// does not move them up the mentioned user's timeline.
+func (r *mentionRepository) setMentions(source *gorm.DB, userIDs []uuid.UUID, mention func(userID uuid.UUID) *domain.Mention) ([]uuid.UUID, error) {
+	var added []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Model(&domain.Mention{}).Where(source).Pluck("user_id", &existing).Error; err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if !slices.Contains(existing, userID) {
+				added = append(added, userID)
+			}
+		}
+
+		stale := tx.Where(source)
+		if len(userIDs) > 0 {
+			stale = stale.Where("user_id NOT IN ?", userIDs)
+		}
+		if err := stale.Delete(&domain.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		mentions := make([]*domain.Mention, len(added))
+		for i, userID := range added {
+			mentions[i] = mention(userID)
+			mentions[i].ID = uuid.New()
+			mentions[i].CreatedAt = time.Now()
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+func (r *mentionRepository) GetPostMentions(postIDs []uuid.UUID) (map[uuid.UUID][]*domain.User, error) {
+	return r.mentionedUsers("m.post_id", r.db.Where("m.post_id IN ? AND m.comment_id IS NULL", postIDs), len(postIDs))
+}
+
+func (r *mentionRepository) GetCommentMentions(commentIDs []uuid.UUID) (map[uuid.UUID][]*domain.User, error) {
+	return r.mentionedUsers("m.comment_id", r.db.Where("m.comment_id IN ?", commentIDs), len(commentIDs))
+}
+
+// mentionedUsers groups the users mentioned by the mentions matching filter
+// by the column identifying their source.
+func (r *mentionRepository) mentionedUsers(sourceColumn string, filter *gorm.DB, count int) (map[uuid.UUID][]*domain.User, error) {
+	users := make(map[uuid.UUID][]*domain.User, count)
+	if count == 0 {
+		return users, nil
+	}
+
+	var rows []struct {
+		domain.User
+		SourceID uuid.UUID
+	}
+	err := r.db.Table("mentions m").
+		Select("users.*, " + sourceColumn + " AS source_id").
+		Joins("JOIN users ON users.id = m.user_id AND users.deleted_at IS NULL").
+		Where(filter).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		users[rows[i].SourceID] = append(users[rows[i].SourceID], &rows[i].User)
+	}
+	return users, nil
+}
+
+func (r *mentionRepository) GetByUserID(userID string, query domain.PageQuery) ([]*domain.Mention, error) {
+	var mentions []*domain.Mention
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.db.Where("user_id = ? AND author_id <> ?", uid, uid).
+		Where("EXISTS (SELECT 1 FROM posts p WHERE p.id = mentions.post_id AND p.deleted_at IS NULL AND ?)", postVisibleTo("p", uid)).
+		Where("comment_id IS NULL OR EXISTS (SELECT 1 FROM comments c WHERE c.id = mentions.comment_id AND c.deleted_at IS NULL)")
+	tx, err = excludeBlocked(tx, "mentions.author_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginate(tx, query, &mentions); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageRepository(db *gorm.DB) domain.MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) Create(message *domain.Message) error {
+	message.ID = uuid.New()
+	message.CreatedAt = time.Now()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Conversation{}).
+			Where("id = ?", message.ConversationID).
+			Update("last_message_at", message.CreatedAt).Error
+	})
+}
+
+func (r *messageRepository) GetByConversationID(conversationID uuid.UUID, query domain.PageQuery) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	tx := r.db.Where("conversation_id = ?", conversationID)
+	if err := paginate(tx, query, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *messageRepository) GetLatest(conversationIDs []uuid.UUID) (map[uuid.UUID]*domain.Message, error) {
+	latest := make(map[uuid.UUID]*domain.Message, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return latest, nil
+	}
+
+	var messages []*domain.Message
+	err := r.db.Raw(`
+		SELECT DISTINCT ON (conversation_id) * FROM messages
+		WHERE conversation_id IN ?
+		ORDER BY conversation_id, created_at DESC, id DESC`, conversationIDs).
+		Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, message := range messages {
+		latest[message.ConversationID] = message
+	}
+	return latest, nil
+}
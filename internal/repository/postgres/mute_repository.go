@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type muteRepository struct {
+	db *gorm.DB
+}
+
+func NewMuteRepository(db *gorm.DB) domain.MuteRepository {
+	return &muteRepository{db: db}
+}
+
+func (r *muteRepository) Create(mute *domain.Mute) error {
+	mute.CreatedAt = time.Now()
+	// Muting someone twice is a no-op rather than an error
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mute).Error
+}
+
+func (r *muteRepository) Delete(muterID string, mutedID string) error {
+	muterUID, err := parseID(muterID, "user")
+	if err != nil {
+		return err
+	}
+	mutedUID, err := parseID(mutedID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Where("muter_id = ? AND muted_id = ?", muterUID, mutedUID).
+		Delete(&domain.Mute{}).Error
+}
+
+func (r *muteRepository) GetMuted(userID string, page int, limit int) ([]*domain.User, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := r.db.Joins("JOIN mutes ON mutes.muted_id = users.id").
+		Where("mutes.muter_id = ?", uid).
+		Order("mutes.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *muteRepository) GetMuterIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&domain.Mute{}).Where("muted_id = ?", userID).Pluck("muter_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// upsertNotificationSQL creates a notification, or folds it into the user's
+// unread notification of the same group, making it the latest activity.
+const upsertNotificationSQL = `
+INSERT INTO notifications (id, user_id, type, group_key, actor_id, actor_count, post_id, comment_id, created_at)
+VALUES (@id, @user_id, @type, @group_key, @actor_id, 1, @post_id, @comment_id, @now)
+ON CONFLICT (user_id, group_key) WHERE read_at IS NULL
+DO UPDATE SET actor_id = EXCLUDED.actor_id, comment_id = EXCLUDED.comment_id, created_at = EXCLUDED.created_at
+RETURNING id`
+
+const addActorSQL = `
+INSERT INTO notification_actors (notification_id, actor_id, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT (notification_id, actor_id) DO UPDATE SET created_at = EXCLUDED.created_at`
+
+const countActorsSQL = `
+UPDATE notifications
+SET actor_count = (SELECT COUNT(*) FROM notification_actors WHERE notification_id = @id)
+WHERE id = @id
+RETURNING actor_count`
+
+// notifyFollowersSQL notifies every follower of the actor who has not
+// switched the type off, in one statement.
+const notifyFollowersSQL = `
+WITH inserted AS (
+	INSERT INTO notifications (id, user_id, type, group_key, actor_id, actor_count, post_id, comment_id, created_at)
+	SELECT gen_random_uuid(), f.follower_id, @type, @group_key, @actor_id, 1, @post_id, @comment_id, @now
+	FROM follows f
+	WHERE f.followee_id = @actor_id
+		AND NOT EXISTS (
+			SELECT 1 FROM notification_preferences np
+			WHERE np.user_id = f.follower_id AND np.type = @type AND NOT np.enabled
+		)
+	ON CONFLICT (user_id, group_key) WHERE read_at IS NULL DO NOTHING
+	RETURNING id
+)
+INSERT INTO notification_actors (notification_id, actor_id, created_at)
+SELECT id, @actor_id, @now FROM inserted`
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) domain.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func notificationArgs(n *domain.Notification) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         n.ID,
+		"user_id":    n.UserID,
+		"type":       n.Type,
+		"group_key":  n.GroupKey,
+		"actor_id":   n.ActorID,
+		"post_id":    n.PostID,
+		"comment_id": n.CommentID,
+		"now":        n.CreatedAt,
+	}
+}
+
+func (r *notificationRepository) Add(n *domain.Notification) error {
+	n.ID = uuid.New()
+	n.CreatedAt = time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// On conflict the existing notification's id comes back
+		if err := tx.Raw(upsertNotificationSQL, notificationArgs(n)).Scan(&n.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(addActorSQL, n.ID, n.ActorID, n.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Raw(countActorsSQL, map[string]interface{}{"id": n.ID}).Scan(&n.ActorCount).Error
+	})
+}
+
+func (r *notificationRepository) AddForFollowers(n *domain.Notification) error {
+	n.CreatedAt = time.Now()
+	return r.db.Exec(notifyFollowersSQL, notificationArgs(n)).Error
+}
+
+func (r *notificationRepository) GetByUserID(userID string, query domain.PageQuery, unreadOnly bool) ([]*domain.Notification, error) {
+	var notifications []*domain.Notification
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.db.Where("user_id = ?", uid)
+	if unreadOnly {
+		tx = tx.Where("read_at IS NULL")
+	}
+	if err := paginate(tx, query, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) GetActors(notificationIDs []uuid.UUID, limit int) (map[uuid.UUID][]*domain.User, error) {
+	actors := make(map[uuid.UUID][]*domain.User, len(notificationIDs))
+	if len(notificationIDs) == 0 {
+		return actors, nil
+	}
+
+	latest := r.db.Table("notification_actors").
+		Select("notification_id, actor_id, created_at, row_number() OVER (PARTITION BY notification_id ORDER BY created_at DESC) AS position").
+		Where("notification_id IN ?", notificationIDs)
+
+	var rows []struct {
+		domain.User
+		NotificationID uuid.UUID
+	}
+	err := r.db.Table("(?) AS na", latest).
+		Select("users.*, na.notification_id").
+		Joins("JOIN users ON users.id = na.actor_id AND users.deleted_at IS NULL").
+		Where("na.position <= ?", limit).
+		Order("na.created_at DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		actors[rows[i].NotificationID] = append(actors[rows[i].NotificationID], &rows[i].User)
+	}
+	return actors, nil
+}
+
+func (r *notificationRepository) CountUnread(userID string) (int64, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", uid).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(userID string, id string) error {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return err
+	}
+	nid, err := parseID(id, "notification")
+	if err != nil {
+		return err
+	}
+
+	var notification domain.Notification
+	if err := r.db.Where("id = ? AND user_id = ?", nid, uid).First(&notification).Error; err != nil {
+		return translateError(err, "notification not found")
+	}
+	return r.db.Model(&notification).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID string) error {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", uid).
+		Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepository) GetDisabledTypes(userID string) ([]domain.NotificationType, error) {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var types []domain.NotificationType
+	err = r.db.Table("notification_preferences").
+		Where("user_id = ? AND NOT enabled", uid).
+		Pluck("type", &types).Error
+	return types, err
+}
+
+func (r *notificationRepository) SetPreference(userID string, notificationType domain.NotificationType, enabled bool) error {
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return err
+	}
+	return r.db.Exec(`
+		INSERT INTO notification_preferences (user_id, type, enabled) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled`,
+		uid, notificationType, enabled).Error
+}
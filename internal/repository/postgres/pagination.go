@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"slices"
+	"socialnetwork/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// paginate applies keyset pagination over (created_at, id) and always returns
+// rows newest first, regardless of the direction the cursor walks in.
+func paginate[T any](tx *gorm.DB, query domain.PageQuery, rows *[]T) error {
+	if query.Cursor != nil {
+		if query.Backward() {
+			tx = tx.Where("(created_at, id) > (?, ?)", query.Cursor.CreatedAt, query.Cursor.ID)
+		} else {
+			tx = tx.Where("(created_at, id) < (?, ?)", query.Cursor.CreatedAt, query.Cursor.ID)
+		}
+	}
+
+	if query.Backward() {
+		tx = tx.Order("created_at ASC, id ASC")
+	} else {
+		tx = tx.Order("created_at DESC, id DESC")
+	}
+
+	if err := tx.Limit(query.Limit).Find(rows).Error; err != nil {
+		return err
+	}
+
+	if query.Backward() {
+		slices.Reverse(*rows)
+	}
+	return nil
+}
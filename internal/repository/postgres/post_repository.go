@@ -1,11 +1,14 @@
 package postgres
 
 import (
+	"database/sql"
+	"slices"
 	"socialnetwork/internal/domain"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type postRepository struct {
@@ -16,27 +19,45 @@ func NewPostRepository(db *gorm.DB) domain.PostRepository {
 	return &postRepository{db: db}
 }
 
-func (r *postRepository) GetByID(id string) (*domain.Post, error) {
+func (r *postRepository) GetByID(viewerID string, id string) (*domain.Post, error) {
 	var post domain.Post
-	uid, err := uuid.Parse(id)
+	uid, err := parseID(id, "post")
 	if err != nil {
 		return nil, err
 	}
-	
-	if err := r.db.Where("id = ? AND deleted_at IS NULL", uid).First(&post).Error; err != nil {
+
+	tx, err := visiblePosts(r.db.Where("id = ? AND deleted_at IS NULL", uid), viewerID)
+	if err != nil {
 		return nil, err
 	}
+	if err := tx.First(&post).Error; err != nil {
+		return nil, translateError(err, "post not found")
+	}
 	return &post, nil
 }
 
-func (r *postRepository) GetByUserID(userID string) ([]*domain.Post, error) {
+func (r *postRepository) IsVisible(viewerID string, id uuid.UUID) (bool, error) {
+	tx, err := visiblePosts(r.db.Model(&domain.Post{}).Where("id = ? AND deleted_at IS NULL", id), viewerID)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	err = tx.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *postRepository) GetByUserID(viewerID string, userID string, query domain.PageQuery) ([]*domain.Post, error) {
 	var posts []*domain.Post
-	uid, err := uuid.Parse(userID)
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := visiblePosts(r.db.Where("user_id = ? AND deleted_at IS NULL", uid), viewerID)
 	if err != nil {
 		return nil, err
 	}
-	
-	if err := r.db.Where("user_id = ? AND deleted_at IS NULL", uid).Order("created_at DESC").Find(&posts).Error; err != nil {
+	if err := paginate(tx, query, &posts); err != nil {
 		return nil, err
 	}
 	return posts, nil
@@ -46,40 +67,307 @@ func (r *postRepository) Create(post *domain.Post) error {
 	post.ID = uuid.New()
 	post.CreatedAt = time.Now()
 	post.UpdatedAt = time.Now()
-	return r.db.Create(post).Error
+	return translateError(r.db.Create(post).Error, "post not found")
 }
 
 func (r *postRepository) Update(post *domain.Post) error {
-	post.UpdatedAt = time.Now()
-	return r.db.Model(post).Where("id = ? AND deleted_at IS NULL", post.ID).
-		Updates(map[string]interface{}{
-			"content":    post.Content,
-			"media":      post.Media,
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the stored version so concurrent edits each keep the one they
+		// replace
+		var current domain.Post
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", post.ID).
+			First(&current).Error; err != nil {
+			return translateError(err, "post not found")
+		}
+
+		now := time.Now()
+		post.UpdatedAt = now
+		changes := map[string]interface{}{
+			"visibility": post.Visibility,
 			"updated_at": post.UpdatedAt,
-		}).Error
+		}
+		if post.Content != current.Content || !slices.Equal(post.Media, current.Media) {
+			written := current.CreatedAt
+			if current.EditedAt != nil {
+				written = *current.EditedAt
+			}
+			if err := tx.Create(&domain.PostRevision{
+				ID:        uuid.New(),
+				PostID:    current.ID,
+				Content:   current.Content,
+				Media:     current.Media,
+				CreatedAt: written,
+			}).Error; err != nil {
+				return err
+			}
+
+			post.EditedAt = &now
+			changes["content"] = post.Content
+			changes["media"] = post.Media
+			changes["edited_at"] = now
+		}
+		return tx.Model(post).Where("id = ?", post.ID).Updates(changes).Error
+	})
+}
+
+func (r *postRepository) GetRevisions(postID uuid.UUID) ([]*domain.PostRevision, error) {
+	var revisions []*domain.PostRevision
+	if err := r.db.Where("post_id = ?", postID).
+		Order("created_at ASC, id ASC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
 }
 
 func (r *postRepository) Delete(id string) error {
-	uid, err := uuid.Parse(id)
+	uid, err := parseID(id, "post")
 	if err != nil {
 		return err
 	}
 	return r.db.Model(&domain.Post{}).
-		Where("id = ? AND deleted_at IS NULL", uid).
+		Where("(id = ? OR repost_of_id = ?) AND deleted_at IS NULL", uid, uid).
 		Update("deleted_at", time.Now()).Error
 }
 
-func (r *postRepository) GetFeed(page, limit int) ([]*domain.Post, error) {
+func (r *postRepository) GetRepost(userID string, postID string) (*domain.Post, error) {
+	userUID, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	postUID, err := parseID(postID, "post")
+	if err != nil {
+		return nil, err
+	}
+
+	var repost domain.Post
+	if err := r.db.Where("user_id = ? AND repost_of_id = ? AND deleted_at IS NULL", userUID, postUID).
+		First(&repost).Error; err != nil {
+		return nil, translateError(err, "repost not found")
+	}
+	return &repost, nil
+}
+
+func (r *postRepository) GetReposts(postID string) ([]*domain.Post, error) {
+	uid, err := parseID(postID, "post")
+	if err != nil {
+		return nil, err
+	}
+
+	var reposts []*domain.Post
+	if err := r.db.Where("repost_of_id = ? AND deleted_at IS NULL", uid).
+		Find(&reposts).Error; err != nil {
+		return nil, err
+	}
+	return reposts, nil
+}
+
+func (r *postRepository) DeleteRepost(userID string, postID string) error {
+	userUID, err := parseID(userID, "user")
+	if err != nil {
+		return err
+	}
+	postUID, err := parseID(postID, "post")
+	if err != nil {
+		return err
+	}
+	result := r.db.Model(&domain.Post{}).
+		Where("user_id = ? AND repost_of_id = ? AND deleted_at IS NULL", userUID, postUID).
+		Update("deleted_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewNotFoundError("repost not found")
+	}
+	return nil
+}
+
+func (r *postRepository) CountReposts(postIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RepostOfID uuid.UUID
+		Count      int64
+	}
+	if err := r.db.Model(&domain.Post{}).
+		Select("repost_of_id, COUNT(*) AS count").
+		Where("repost_of_id IN ? AND deleted_at IS NULL", postIDs).
+		Group("repost_of_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.RepostOfID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *postRepository) GetRepostedPostIDs(userID string, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	reposted := make(map[uuid.UUID]bool, len(postIDs))
+	if len(postIDs) == 0 {
+		return reposted, nil
+	}
+
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	if err := r.db.Model(&domain.Post{}).
+		Where("user_id = ? AND repost_of_id IN ? AND deleted_at IS NULL", uid, postIDs).
+		Pluck("repost_of_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		reposted[id] = true
+	}
+	return reposted, nil
+}
+
+func (r *postRepository) GetHomeFeed(userID string, query domain.PageQuery) ([]*domain.Post, error) {
 	var posts []*domain.Post
-	offset := (page - 1) * limit
+	uid, err := parseID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
 
-	if err := r.db.Where("deleted_at IS NULL").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
-		Find(&posts).Error; err != nil {
+	following := r.db.Model(&domain.Follow{}).Select("followee_id").Where("follower_id = ?", uid)
+	tx := r.db.Model(&domain.Post{}).Where("deleted_at IS NULL").
+		Where("user_id = ? OR user_id IN (?)", uid, following)
+	tx, err = visiblePosts(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tx, err = excludeMuted(tx, "posts.user_id", userID); err != nil {
+		return nil, err
+	}
+	if err := paginate(r.latestShares(tx), query, &posts); err != nil {
 		return nil, err
 	}
 
 	return posts, nil
 }
+
+// Search returns posts matching every term of the query, best first.
+func (r *postRepository) Search(query domain.PostSearchQuery) ([]*domain.PostSearchHit, error) {
+	tsquery := toTSQuery(query.Terms)
+
+	matches, err := visiblePosts(r.db.Model(&domain.Post{}).
+		Select("posts.*, "+postSearchScore+" AS search_score", tsquery).
+		Where("deleted_at IS NULL AND "+postSearchVector+" @@ to_tsquery('"+searchConfig+"', ?)", tsquery),
+		query.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.db.Table("(?) AS matches", matches)
+	if query.Cursor != nil {
+		tx = tx.Where("(search_score, id) < (?, ?)", query.Cursor.Score, query.Cursor.ID)
+	}
+
+	var rows []struct {
+		domain.Post
+		SearchScore float64
+	}
+	if err := tx.Order("search_score DESC, id DESC").Limit(query.Limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]*domain.PostSearchHit, len(rows))
+	for i := range rows {
+		hits[i] = &domain.PostSearchHit{Post: &rows[i].Post, Score: rows[i].SearchScore}
+	}
+	return hits, nil
+}
+
+func (r *postRepository) GetByHashtag(viewerID string, tag string, query domain.PageQuery) ([]*domain.Post, error) {
+	var posts []*domain.Post
+
+	tagged := r.db.Table("post_hashtags ph").
+		Select("ph.post_id").
+		Joins("JOIN hashtags h ON h.id = ph.hashtag_id").
+		Where("h.tag = ?", tag)
+	tx, err := visiblePosts(r.db.Where("deleted_at IS NULL AND id IN (?)", tagged), viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginate(tx, query, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *postRepository) GetByIDs(viewerID string, ids []uuid.UUID) ([]*domain.Post, error) {
+	var posts []*domain.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	tx, err := visiblePosts(r.db.Where("id IN ? AND deleted_at IS NULL", ids), viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// latestShares keeps, of the posts tx selects, only the latest appearance of
+// each post: either the post itself or one of its reposts. A post is always
+// older than its reposts, so a share is dropped when tx also selects a newer
+// repost of the same post. Each row is checked on its own, so pages are still
+// read along the keyset index.
+func (r *postRepository) latestShares(tx *gorm.DB) *gorm.DB {
+	shares := tx.Session(&gorm.Session{})
+	return shares.Where("NOT EXISTS (SELECT 1 FROM (?) AS newer "+
+		"WHERE newer.repost_of_id = COALESCE(posts.repost_of_id, posts.id) "+
+		"AND (newer.created_at, newer.id) > (posts.created_at, posts.id))", shares)
+}
+
+// visiblePosts limits tx to the posts the viewer may see, and to reposts of
+// live posts the viewer may see. Internal reads, passing no viewer, see every
+// post.
+func visiblePosts(tx *gorm.DB, viewerID string) (*gorm.DB, error) {
+	if viewerID == "" {
+		return tx, nil
+	}
+	uid, err := parseID(viewerID, "user")
+	if err != nil {
+		return nil, err
+	}
+	tx, err = excludeBlocked(tx, "posts.user_id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+	originalNotBlocked, err := notBlocked("original.user_id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Where(postVisibleTo("posts", uid)).
+		Where("posts.repost_of_id IS NULL OR EXISTS (SELECT 1 FROM posts original "+
+			"WHERE original.id = posts.repost_of_id AND original.deleted_at IS NULL AND ? AND ?)",
+			originalNotBlocked, postVisibleTo("original", uid)), nil
+}
+
+// postVisibleTo is the condition on the posts table, under alias, that
+// Visibility describes: authors see all their posts, mentioned users all but
+// private ones, followers public and followers-only ones, and everyone else
+// public posts of public accounts.
+func postVisibleTo(alias string, viewerID uuid.UUID) clause.NamedExpr {
+	return clause.NamedExpr{
+		SQL: "(" + alias + ".user_id = @viewer" +
+			" OR (" + alias + ".visibility <> 'private' AND EXISTS (SELECT 1 FROM mentions WHERE mentions.post_id = " + alias + ".id AND mentions.comment_id IS NULL AND mentions.user_id = @viewer))" +
+			" OR (" + alias + ".visibility IN ('public', 'followers') AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = @viewer AND follows.followee_id = " + alias + ".user_id))" +
+			" OR (" + alias + ".visibility = 'public' AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = " + alias + ".user_id AND users.is_private)))",
+		Vars: []interface{}{sql.Named("viewer", viewerID)},
+	}
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"fmt"
+	"socialnetwork/internal/domain"
+	"strings"
+)
+
+const (
+	// searchConfig is the text search configuration posts are indexed with;
+	// it must match the expression of idx_posts_content_fts.
+	searchConfig = "english"
+
+	// searchRecencyDays is how many days newer a post has to be to outrank a
+	// perfect match. Relevance is normalized to [0, 1) and the recency term
+	// depends only on created_at, so scores are stable across requests and
+	// usable in cursors.
+	searchRecencyDays = 90
+)
+
+// postSearchVector is the indexed document of a post.
+const postSearchVector = "to_tsvector('" + searchConfig + "', content)"
+
+// postSearchScore ranks a post against the tsquery bound to its placeholder.
+// Both parts are computed in double precision so the score survives the
+// round trip through a cursor unchanged.
+var postSearchScore = fmt.Sprintf(
+	"ts_rank_cd(%s, to_tsquery('%s', ?), 32)::float8 + EXTRACT(EPOCH FROM created_at)::float8 / 86400 / %d",
+	postSearchVector, searchConfig, searchRecencyDays,
+)
+
+// toTSQuery renders parsed search terms in to_tsquery syntax. Terms only
+// contain letters and digits, so quoting each word is enough to keep user
+// input from being read as operators.
+func toTSQuery(terms []domain.SearchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words := make([]string, len(term.Words))
+		for i, word := range term.Words {
+			words[i] = "'" + word + "'"
+		}
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+
+		part := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			part = "(" + part + ")"
+		}
+		if term.Exclude {
+			part = "!" + part
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
@@ -1,10 +1,13 @@
 package postgres
 
 import (
+	"errors"
 	"socialnetwork/internal/domain"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -17,13 +20,13 @@ func NewUserRepository(db *gorm.DB) domain.UserRepository {
 
 func (r *userRepository) GetByID(id string) (*domain.User, error) {
 	var user domain.User
-	uid, err := uuid.Parse(id)
+	uid, err := parseID(id, "user")
 	if err != nil {
 		return nil, err
 	}
 	
 	if err := r.db.Where("id = ?", uid).First(&user).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "user not found")
 	}
 	return &user, nil
 }
@@ -31,14 +34,90 @@ func (r *userRepository) GetByID(id string) (*domain.User, error) {
 func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
 	var user domain.User
 	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "user not found")
+	}
+	return &user, nil
+}
+
+// GetByUsername looks a user up by username, ignoring case.
+func (r *userRepository) GetByUsername(username string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.Where("lower(username) = lower(?) AND deleted_at IS NULL", username).First(&user).Error; err != nil {
+		return nil, translateError(err, "user not found")
 	}
 	return &user, nil
 }
 
+// GetByUsernames looks up the users with any of the usernames, ignoring
+// case. Usernames nobody has are skipped.
+func (r *userRepository) GetByUsernames(usernames []string) ([]*domain.User, error) {
+	var users []*domain.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+	if err := r.db.Where("lower(username) IN ? AND deleted_at IS NULL", lowered).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Search finds users whose username or full name resemble query, using
+// trigram similarity so typos and partial names still match.
+func (r *userRepository) Search(viewerID string, query string, page int, limit int) ([]*domain.User, error) {
+	tx, err := excludeBlocked(r.db, "users.id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := tx.
+		Where("deleted_at IS NULL AND (username % ? OR ? <% full_name OR username ILIKE ? ESCAPE '\\')",
+			query, query, escapeLike(query)+"%").
+		Order(clause.Expr{
+			SQL:  "GREATEST(similarity(username, ?), word_similarity(?, full_name)) DESC, username",
+			Vars: []interface{}{query, query},
+		}).
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SearchByPrefix returns users whose username starts with prefix, shortest
+// first, for typeahead.
+func (r *userRepository) SearchByPrefix(viewerID string, prefix string, limit int) ([]*domain.User, error) {
+	tx, err := excludeBlocked(r.db, "users.id", viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := tx.
+		Where("deleted_at IS NULL AND lower(username) LIKE lower(?) ESCAPE '\\'", escapeLike(prefix)+"%").
+		Order("length(username), username").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) Create(user *domain.User) error {
 	user.ID = uuid.New()
-	return r.db.Create(user).Error
+	if err := r.db.Create(user).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.NewConflictError("username or email already taken")
+		}
+		return err
+	}
+	return nil
 }
 
 func (r *userRepository) Update(user *domain.User) error {
@@ -46,7 +125,7 @@ func (r *userRepository) Update(user *domain.User) error {
 }
 
 func (r *userRepository) Delete(id string) error {
-	uid, err := uuid.Parse(id)
+	uid, err := parseID(id, "user")
 	if err != nil {
 		return err
 	}
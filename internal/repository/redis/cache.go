@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"expvar"
+	"log"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const cachePrefix = "cache:"
+
+// cacheStats exposes hit/miss counters per cache under /debug/vars.
+var cacheStats = expvar.NewMap("cache")
+
+// cache implements cache-aside reads on top of Redis. Values are gob encoded
+// rather than JSON so fields hidden from API responses, such as password
+// hashes, survive the round trip.
+type cache struct {
+	client *goredis.Client
+	name   string
+	group  singleflight.Group
+}
+
+func newCache(client *goredis.Client, name string) *cache {
+	return &cache{client: client, name: name}
+}
+
+// getOrLoad returns the value stored under key, calling load on a miss and
+// storing its result for ttl. Concurrent misses on the same key share a
+// single load, so an expired hot entry does not stampede the database. Every
+// caller decodes its own copy and may modify it freely. Redis failures fall
+// back to load.
+func getOrLoad[T any](c *cache, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	var value T
+	if ttl <= 0 {
+		return load()
+	}
+
+	ctx := context.Background()
+	raw, err := c.client.Get(ctx, key).Bytes()
+	if err == nil {
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&value); err == nil {
+			cacheStats.Add(c.name+"_hits", 1)
+			return value, nil
+		}
+		log.Printf("cache: dropping undecodable entry %s", key)
+	} else if !errors.Is(err, goredis.Nil) {
+		log.Printf("cache: get %s: %v", key, err)
+	}
+	cacheStats.Add(c.name+"_misses", 1)
+
+	shared, err, _ := c.group.Do(key, func() (interface{}, error) {
+		loaded, err := load()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(loaded); err != nil {
+			return nil, err
+		}
+		if err := c.client.Set(ctx, key, buf.Bytes(), ttl).Err(); err != nil {
+			log.Printf("cache: set %s: %v", key, err)
+		}
+		return buf.Bytes(), nil
+	})
+	if err != nil {
+		return value, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(shared.([]byte))).Decode(&value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// getOrLoadIndexed works like getOrLoad and also records key in each index
+// set, so every entry of a group (e.g. all page sizes of a feed) can be
+// dropped at once with invalidateIndex.
+func getOrLoadIndexed[T any](c *cache, indexes []string, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	if ttl > 0 {
+		ctx := context.Background()
+		_, err := c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, index := range indexes {
+				pipe.SAdd(ctx, index, key)
+				pipe.Expire(ctx, index, ttl)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("cache: index %v: %v", indexes, err)
+		}
+	}
+	return getOrLoad(c, key, ttl, load)
+}
+
+// invalidate removes keys from the cache. Failures are logged rather than
+// returned: the write they follow has already succeeded, and entries expire
+// on their own.
+func (c *cache) invalidate(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := c.client.Del(context.Background(), keys...).Err(); err != nil {
+		log.Printf("cache: invalidate %v: %v", keys, err)
+	}
+}
+
+// invalidateIndex removes every key recorded in index, and the index itself.
+func (c *cache) invalidateIndex(index string) {
+	ctx := context.Background()
+	keys, err := c.client.SMembers(ctx, index).Result()
+	if err != nil {
+		log.Printf("cache: read index %s: %v", index, err)
+		return
+	}
+	c.invalidate(append(keys, index)...)
+}
@@ -0,0 +1,169 @@
+package redis
+
+import (
+	"fmt"
+	"socialnetwork/internal/domain"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	postCachePrefix    = cachePrefix + "post:"
+	viewerFeedPrefix   = cachePrefix + "feed:viewer:"
+	allFeedsCacheIndex = cachePrefix + "feed:all"
+)
+
+// cachedPostRepository caches single posts and the first page of each home
+// feed. Deeper pages are addressed by cursor and are read straight
+// from the wrapped repository, as are methods it does not override.
+//
+// Home feeds are only invalidated for the author of a changed post, and for
+// its reposters when it is deleted; followers see the change once their
+// cached page expires after the feed TTL. Blocks,
+// mutes and follows, which are filtered into each viewer's feed pages, drop
+// the pages of the viewers involved through FeedCache. Changes to who may see
+// a post drop every cached feed page. Single posts are cached once for
+// everyone and checked against the database for whether the viewer may see
+// them on every read.
+type cachedPostRepository struct {
+	domain.PostRepository
+	cache     *cache
+	entityTTL time.Duration
+	feedTTL   time.Duration
+}
+
+func NewCachedPostRepository(next domain.PostRepository, client *goredis.Client, entityTTL, feedTTL time.Duration) domain.PostRepository {
+	return &cachedPostRepository{
+		PostRepository: next,
+		cache:          newCache(client, "post"),
+		entityTTL:      entityTTL,
+		feedTTL:        feedTTL,
+	}
+}
+
+func postCacheKey(id string) string {
+	return postCachePrefix + id
+}
+
+// viewerFeedCacheIndex records both feeds cached for a viewer.
+func viewerFeedCacheIndex(viewerID string) string {
+	return viewerFeedPrefix + viewerID
+}
+
+func feedPageCacheKey(index string, limit int) string {
+	return fmt.Sprintf("%s:limit:%d", index, limit)
+}
+
+func (r *cachedPostRepository) GetByID(viewerID string, id string) (*domain.Post, error) {
+	post, err := getOrLoad(r.cache, postCacheKey(id), r.entityTTL, func() (*domain.Post, error) {
+		return r.PostRepository.GetByID("", id)
+	})
+	if err != nil || viewerID == "" || viewerID == post.UserID.String() {
+		return post, err
+	}
+
+	visible, err := r.PostRepository.IsVisible(viewerID, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, domain.NewNotFoundError("post not found")
+	}
+	return post, nil
+}
+
+func (r *cachedPostRepository) GetHomeFeed(userID string, query domain.PageQuery) ([]*domain.Post, error) {
+	if query.Cursor != nil {
+		return r.PostRepository.GetHomeFeed(userID, query)
+	}
+	index := viewerFeedCacheIndex(userID)
+	return getOrLoadIndexed(r.cache, []string{allFeedsCacheIndex, index}, feedPageCacheKey(index+":home", query.Limit), r.feedTTL, func() ([]*domain.Post, error) {
+		return r.PostRepository.GetHomeFeed(userID, query)
+	})
+}
+
+func (r *cachedPostRepository) Create(post *domain.Post) error {
+	if err := r.PostRepository.Create(post); err != nil {
+		return err
+	}
+	r.invalidateFeeds(post.UserID.String())
+	return nil
+}
+
+func (r *cachedPostRepository) Update(post *domain.Post) error {
+	// Look the post up first to tell whether who may see it can change
+	previous, _ := r.GetByID("", post.ID.String())
+
+	if err := r.PostRepository.Update(post); err != nil {
+		return err
+	}
+	r.cache.invalidate(postCacheKey(post.ID.String()))
+	// Posts that are not public reach those they mention, which an edit
+	// changes, besides followers
+	if previous == nil || previous.Visibility != domain.VisibilityPublic || post.Visibility != domain.VisibilityPublic {
+		r.cache.invalidateIndex(allFeedsCacheIndex)
+	} else {
+		r.invalidateFeeds(post.UserID.String())
+	}
+	return nil
+}
+
+func (r *cachedPostRepository) Delete(id string) error {
+	// Look the post and its reposts up first so the home feeds of the author
+	// and the reposters can be invalidated.
+	post, _ := r.GetByID("", id)
+	reposts, repostsErr := r.PostRepository.GetReposts(id)
+
+	if err := r.PostRepository.Delete(id); err != nil {
+		return err
+	}
+	r.cache.invalidate(postCacheKey(id))
+	if post == nil || repostsErr != nil {
+		r.cache.invalidateIndex(allFeedsCacheIndex)
+	} else {
+		r.invalidateFeeds(post.UserID.String())
+	}
+	for _, repost := range reposts {
+		r.cache.invalidate(postCacheKey(repost.ID.String()))
+		r.invalidateFeeds(repost.UserID.String())
+	}
+	return nil
+}
+
+func (r *cachedPostRepository) DeleteRepost(userID string, postID string) error {
+	// Look the repost up first, as it is cached under its own id
+	repost, _ := r.PostRepository.GetRepost(userID, postID)
+
+	if err := r.PostRepository.DeleteRepost(userID, postID); err != nil {
+		return err
+	}
+	if repost != nil {
+		r.cache.invalidate(postCacheKey(repost.ID.String()))
+	}
+	r.invalidateFeeds(userID)
+	return nil
+}
+
+func (r *cachedPostRepository) invalidateFeeds(authorID string) {
+	r.cache.invalidateIndex(viewerFeedCacheIndex(authorID))
+}
+
+type feedCache struct {
+	cache *cache
+}
+
+// NewFeedCache drops the feed pages cached by the post repository.
+func NewFeedCache(client *goredis.Client) domain.FeedCache {
+	return &feedCache{cache: newCache(client, "feed")}
+}
+
+func (f *feedCache) InvalidateViewers(userIDs ...string) {
+	for _, userID := range userIDs {
+		f.cache.invalidateIndex(viewerFeedCacheIndex(userID))
+	}
+}
+
+func (f *feedCache) InvalidateAll() {
+	f.cache.invalidateIndex(allFeedsCacheIndex)
+}
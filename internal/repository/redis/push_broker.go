@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"socialnetwork/internal/domain"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const pushChannel = "push"
+
+type pushBroker struct {
+	client *goredis.Client
+}
+
+func NewPushBroker(client *goredis.Client) domain.PushBroker {
+	return &pushBroker{client: client}
+}
+
+func (b *pushBroker) Publish(push *domain.Push) error {
+	payload, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(context.Background(), pushChannel, payload).Err()
+}
+
+// Subscribe receives pushes until ctx is done. The client reconnects on its
+// own after network errors; pushes published meanwhile are lost.
+func (b *pushBroker) Subscribe(ctx context.Context, deliver func(push *domain.Push)) error {
+	sub := b.client.Subscribe(ctx, pushChannel)
+	defer sub.Close()
+
+	// Wait for the subscription to be confirmed so early failures surface
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			var push domain.Push
+			if err := json.Unmarshal([]byte(msg.Payload), &push); err != nil {
+				log.Printf("push: dropping malformed message: %v", err)
+				continue
+			}
+			deliver(&push)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"socialnetwork/internal/domain"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	refreshTokenPrefix   = "auth:refresh:"
+	revokedSessionPrefix = "auth:session:revoked:"
+	revokedBeforePrefix  = "auth:user:revoked_before:"
+)
+
+// consumeScript flags a refresh token as used and returns its fields, so two
+// concurrent refreshes with the same token cannot both succeed.
+var consumeScript = goredis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return false
+end
+redis.call("HINCRBY", KEYS[1], "used", 1)
+return redis.call("HGETALL", KEYS[1])
+`)
+
+type tokenRepository struct {
+	client *goredis.Client
+}
+
+func NewTokenRepository(client *goredis.Client) domain.TokenRepository {
+	return &tokenRepository{client: client}
+}
+
+// Refresh tokens are only stored hashed, so a leaked Redis dump cannot be
+// replayed.
+func refreshTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return refreshTokenPrefix + hex.EncodeToString(sum[:])
+}
+
+func (r *tokenRepository) SaveRefreshToken(token string, refreshToken *domain.RefreshToken, ttl time.Duration) error {
+	ctx := context.Background()
+	key := refreshTokenKey(token)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"user_id", refreshToken.UserID,
+			"session_id", refreshToken.SessionID,
+			"issued_at", refreshToken.IssuedAt.UnixNano(),
+			"used", 0,
+		)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *tokenRepository) ConsumeRefreshToken(token string) (*domain.RefreshToken, bool, error) {
+	ctx := context.Background()
+
+	values, err := consumeScript.Run(ctx, r.client, []string{refreshTokenKey(token)}).StringSlice()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, domain.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		fields[values[i]] = values[i+1]
+	}
+
+	issuedAt, err := strconv.ParseInt(fields["issued_at"], 10, 64)
+	if err != nil {
+		return nil, false, err
+	}
+	used, err := strconv.Atoi(fields["used"])
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &domain.RefreshToken{
+		UserID:    fields["user_id"],
+		SessionID: fields["session_id"],
+		IssuedAt:  time.Unix(0, issuedAt),
+	}, used > 1, nil
+}
+
+func (r *tokenRepository) RevokeSession(sessionID string, ttl time.Duration) error {
+	return r.client.Set(context.Background(), revokedSessionPrefix+sessionID, 1, ttl).Err()
+}
+
+func (r *tokenRepository) IsSessionRevoked(sessionID string) (bool, error) {
+	n, err := r.client.Exists(context.Background(), revokedSessionPrefix+sessionID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *tokenRepository) RevokeUserTokens(userID string, before time.Time, ttl time.Duration) error {
+	return r.client.Set(context.Background(), revokedBeforePrefix+userID, before.UnixNano(), ttl).Err()
+}
+
+func (r *tokenRepository) GetUserRevokedBefore(userID string) (time.Time, error) {
+	before, err := r.client.Get(context.Background(), revokedBeforePrefix+userID).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, before), nil
+}
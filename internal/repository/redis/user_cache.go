@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const userCachePrefix = cachePrefix + "user:"
+
+// cachedUserRepository caches users by id. Lookups by email are only used
+// when logging in and registering, and go straight to the wrapped repository.
+type cachedUserRepository struct {
+	domain.UserRepository
+	cache *cache
+	ttl   time.Duration
+}
+
+func NewCachedUserRepository(next domain.UserRepository, client *goredis.Client, ttl time.Duration) domain.UserRepository {
+	return &cachedUserRepository{
+		UserRepository: next,
+		cache:          newCache(client, "user"),
+		ttl:            ttl,
+	}
+}
+
+func userCacheKey(id string) string {
+	return userCachePrefix + id
+}
+
+func (r *cachedUserRepository) GetByID(id string) (*domain.User, error) {
+	return getOrLoad(r.cache, userCacheKey(id), r.ttl, func() (*domain.User, error) {
+		return r.UserRepository.GetByID(id)
+	})
+}
+
+func (r *cachedUserRepository) Create(user *domain.User) error {
+	if err := r.UserRepository.Create(user); err != nil {
+		return err
+	}
+	r.cache.invalidate(userCacheKey(user.ID.String()))
+	return nil
+}
+
+func (r *cachedUserRepository) Update(user *domain.User) error {
+	if err := r.UserRepository.Update(user); err != nil {
+		return err
+	}
+	r.cache.invalidate(userCacheKey(user.ID.String()))
+	return nil
+}
+
+func (r *cachedUserRepository) Delete(id string) error {
+	if err := r.UserRepository.Delete(id); err != nil {
+		return err
+	}
+	r.cache.invalidate(userCacheKey(id))
+	return nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"socialnetwork/internal/domain"
+	"strings"
+)
+
+// localStorage keeps media on the local filesystem. The files are expected to
+// be served under baseURL, e.g. by the API's static file route.
+type localStorage struct {
+	root    string
+	baseURL string
+}
+
+func NewLocalStorage(root string, baseURL string) (domain.MediaStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create media directory: %w", err)
+	}
+	return &localStorage{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Put writes to a temporary file first so a partially written file is never
+// served.
+func (s *localStorage) Put(key string, body []byte, contentType string) error {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStorage) Get(key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.root, filepath.FromSlash(key)))
+}
+
+func (s *localStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
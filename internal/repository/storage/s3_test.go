@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "us-east-1"
+	testBucket    = "media"
+)
+
+// fakeS3 stands in for an S3-compatible store: it keeps objects in memory
+// and rejects requests whose SigV4 signature does not check out.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	// requests records "METHOD escaped-path" of every signed request
+	requests []string
+}
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string]fakeObject{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if problem := verifySignature(r, body); problem != "" {
+		http.Error(w, problem, http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.EscapedPath()
+	f.requests = append(f.requests, r.Method+" "+path)
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[path] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		object, ok := f.objects[path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(object.body)
+	default:
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+	}
+}
+
+// verifySignature recomputes the SigV4 signature of r from its headers and
+// returns what is wrong with it, if anything.
+func verifySignature(r *http.Request, body []byte) string {
+	payloadHash := sha256Hex(body)
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != payloadHash {
+		return fmt.Sprintf("payload hash %q, want %q", got, payloadHash)
+	}
+
+	var credential, signedHeaders, signature string
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return "missing AWS4-HMAC-SHA256 authorization"
+	}
+	for _, part := range strings.Split(auth, ", ") {
+		name, value, _ := strings.Cut(part, "=")
+		switch name {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != len("20060102T150405Z") {
+		return fmt.Sprintf("bad X-Amz-Date %q", amzDate)
+	}
+	scope := amzDate[:8] + "/" + testRegion + "/s3/aws4_request"
+	if credential != testAccessKey+"/"+scope {
+		return fmt.Sprintf("credential %q, want %q", credential, testAccessKey+"/"+scope)
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{amzDate[:8], testRegion, "s3", "aws4_request"} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+		return fmt.Sprintf("signature %q, want %q", signature, want)
+	}
+	return ""
+}
+
+func newTestS3Storage(t *testing.T, endpoint string, publicURL string) *s3Storage {
+	t.Helper()
+	store, err := NewS3Storage(S3Config{
+		Endpoint:  endpoint,
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: testSecretKey,
+		PublicURL: publicURL,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return store.(*s3Storage)
+}
+
+func TestS3StoragePutGet(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store := newTestS3Storage(t, server.URL+"/", "")
+
+	key := "uploads/2024/a photo+1.jpg"
+	body := []byte("not really a jpeg")
+	if err := store.Put(key, body, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := store.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(got) != string(body) {
+		t.Errorf("Get returned %q, want %q", got, body)
+	}
+
+	wantPath := "/media/uploads/2024/a%20photo%2B1.jpg"
+	wantRequests := []string{"PUT " + wantPath, "GET " + wantPath}
+	if strings.Join(fake.requests, "|") != strings.Join(wantRequests, "|") {
+		t.Errorf("requests %q, want %q", fake.requests, wantRequests)
+	}
+	if object := fake.objects[wantPath]; object.contentType != "image/jpeg" {
+		t.Errorf("stored content type %q, want image/jpeg", object.contentType)
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	_, server := newFakeS3(t)
+
+	store := newTestS3Storage(t, server.URL, "")
+	_, err := store.Get("missing.jpg")
+	if err == nil || !strings.Contains(err.Error(), "status 404") || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Errorf("Get of a missing object returned %v, want a 404 error with the response message", err)
+	}
+
+	wrongKey := newTestS3Storage(t, server.URL, "")
+	wrongKey.config.SecretKey = "not-the-secret"
+	err = wrongKey.Put("a.jpg", []byte("x"), "image/jpeg")
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("Put with a wrong secret returned %v, want a 403 error", err)
+	}
+}
+
+func TestS3StorageURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  string
+		publicURL string
+		key       string
+		want      string
+	}{
+		{"bucket under endpoint", "http://localhost:9000/", "", "a/b c.png", "http://localhost:9000/media/a/b%20c.png"},
+		{"public url", "http://localhost:9000", "https://cdn.example.com/", "a.png", "https://cdn.example.com/a.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestS3Storage(t, tt.endpoint, tt.publicURL)
+			if got := store.URL(tt.key); got != tt.want {
+				t.Errorf("URL(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewS3StorageRejectsInvalidEndpoint(t *testing.T) {
+	if _, err := NewS3Storage(S3Config{Endpoint: "not a url", Bucket: testBucket}); err == nil {
+		t.Error("NewS3Storage accepted an endpoint without a host")
+	}
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"socialnetwork/internal/domain"
 	"socialnetwork/internal/middleware"
@@ -10,15 +12,26 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
 type AuthUseCase struct {
-	userRepo    domain.UserRepository
-	jwtSecret   string
+	userRepo        domain.UserRepository
+	tokenRepo       domain.TokenRepository
+	jwtSecret       string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
-func NewAuthUseCase(userRepo domain.UserRepository, jwtSecret string) *AuthUseCase {
+func NewAuthUseCase(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, jwtSecret string, accessTokenTTL time.Duration, refreshTokenTTL time.Duration) *AuthUseCase {
 	return &AuthUseCase{
-		userRepo:    userRepo,
-		jwtSecret:   jwtSecret,
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		jwtSecret:       jwtSecret,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
 	}
 }
 
@@ -34,16 +47,22 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type AuthResponse struct {
-	Token     string       `json:"token"`
-	User      domain.User `json:"user"`
+	Token        string           `json:"token"`
+	RefreshToken string           `json:"refresh_token"`
+	ExpiresIn    int64            `json:"expires_in"`
+	User         *domain.SelfUser `json:"user"`
 }
 
 func (a *AuthUseCase) Register(req *RegisterRequest) (*AuthResponse, error) {
 	// Check if email already exists
 	existingUser, err := a.userRepo.GetByEmail(req.Email)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("email already registered")
+		return nil, domain.NewConflictError("email already registered")
 	}
 
 	// Hash password
@@ -67,16 +86,7 @@ func (a *AuthUseCase) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, err
 	}
 
-	// Generate token
-	token, err := middleware.GenerateToken(user.ID.String(), a.jwtSecret)
-	if err != nil {
-		return nil, err
-	}
-
-	return &AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	return a.issueTokens(user, uuid.NewString())
 }
 
 func (a *AuthUseCase) Login(req *LoginRequest) (*AuthResponse, error) {
@@ -91,14 +101,117 @@ func (a *AuthUseCase) Login(req *LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate token
-	token, err := middleware.GenerateToken(user.ID.String(), a.jwtSecret)
+	return a.issueTokens(user, uuid.NewString())
+}
+
+// Refresh rotates a refresh token: the presented token is spent and a new
+// access/refresh pair is issued for the same session. Presenting a spent
+// token again means it was stolen, so the whole session is revoked.
+func (a *AuthUseCase) Refresh(req *RefreshRequest) (*AuthResponse, error) {
+	refreshToken, reused, err := a.tokenRepo.ConsumeRefreshToken(req.RefreshToken)
+	if errors.Is(err, domain.ErrTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if reused {
+		if err := a.tokenRepo.RevokeSession(refreshToken.SessionID, a.refreshTokenTTL); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	revoked, err := a.isRevoked(refreshToken.UserID, refreshToken.SessionID, refreshToken.IssuedAt, 0)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := a.userRepo.GetByID(refreshToken.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return a.issueTokens(user, refreshToken.SessionID)
+}
+
+// Logout revokes the session the current tokens belong to.
+func (a *AuthUseCase) Logout(sessionID string) error {
+	if sessionID == "" {
+		return domain.NewValidationError("invalid session id")
+	}
+	return a.tokenRepo.RevokeSession(sessionID, a.refreshTokenTTL)
+}
+
+// LogoutAll revokes every token issued to the user so far, on all devices.
+func (a *AuthUseCase) LogoutAll(userID string) error {
+	if userID == "" {
+		return domain.NewValidationError("invalid user id")
+	}
+	return a.tokenRepo.RevokeUserTokens(userID, time.Now(), a.refreshTokenTTL)
+}
+
+// IsTokenRevoked implements middleware.RevocationChecker.
+func (a *AuthUseCase) IsTokenRevoked(claims *middleware.Claims) (bool, error) {
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	// Access tokens carry their issue time in whole seconds, so one issued in
+	// the same second as a logout-all cannot be told apart from one issued
+	// before it, and is rejected too
+	return a.isRevoked(claims.UserID, claims.SessionID, claims.IssuedAt.Time, time.Second)
+}
+
+// isRevoked reports whether the token, issued at issuedAt to the given
+// precision, was revoked. Tokens issued at the cut-off of a logout-all, to
+// that precision, count as issued before it.
+func (a *AuthUseCase) isRevoked(userID string, sessionID string, issuedAt time.Time, precision time.Duration) (bool, error) {
+	revoked, err := a.tokenRepo.IsSessionRevoked(sessionID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	before, err := a.tokenRepo.GetUserRevokedBefore(userID)
+	if err != nil {
+		return false, err
+	}
+	return !before.IsZero() && !issuedAt.After(before.Truncate(precision)), nil
+}
+
+func (a *AuthUseCase) issueTokens(user *domain.User, sessionID string) (*AuthResponse, error) {
+	token, err := middleware.GenerateToken(user.ID.String(), sessionID, a.jwtSecret, a.accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.tokenRepo.SaveRefreshToken(refreshToken, &domain.RefreshToken{
+		UserID:    user.ID.String(),
+		SessionID: sessionID,
+		IssuedAt:  time.Now(),
+	}, a.refreshTokenTTL); err != nil {
+		return nil, err
+	}
+
 	return &AuthResponse{
-		Token: token,
-		User:  *user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(a.accessTokenTTL.Seconds()),
+		User:         domain.NewSelfUser(user),
 	}, nil
 }
+
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
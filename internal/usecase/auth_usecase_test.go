@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"errors"
+	"socialnetwork/internal/domain"
+	"socialnetwork/internal/middleware"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testJWTSecret = "test-secret"
+
+// memoryTokenRepository keeps tokens the way the Redis one does, in memory.
+type memoryTokenRepository struct {
+	mu              sync.Mutex
+	refreshTokens   map[string]*domain.RefreshToken
+	used            map[string]bool
+	revokedSessions map[string]bool
+	revokedBefore   map[string]time.Time
+}
+
+func newMemoryTokenRepository() *memoryTokenRepository {
+	return &memoryTokenRepository{
+		refreshTokens:   map[string]*domain.RefreshToken{},
+		used:            map[string]bool{},
+		revokedSessions: map[string]bool{},
+		revokedBefore:   map[string]time.Time{},
+	}
+}
+
+func (r *memoryTokenRepository) SaveRefreshToken(token string, refreshToken *domain.RefreshToken, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *refreshToken
+	r.refreshTokens[token] = &saved
+	return nil
+}
+
+func (r *memoryTokenRepository) ConsumeRefreshToken(token string) (*domain.RefreshToken, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	refreshToken, ok := r.refreshTokens[token]
+	if !ok {
+		return nil, false, domain.ErrTokenNotFound
+	}
+	reused := r.used[token]
+	r.used[token] = true
+	consumed := *refreshToken
+	return &consumed, reused, nil
+}
+
+func (r *memoryTokenRepository) RevokeSession(sessionID string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedSessions[sessionID] = true
+	return nil
+}
+
+func (r *memoryTokenRepository) IsSessionRevoked(sessionID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.revokedSessions[sessionID], nil
+}
+
+func (r *memoryTokenRepository) RevokeUserTokens(userID string, before time.Time, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedBefore[userID] = before
+	return nil
+}
+
+func (r *memoryTokenRepository) GetUserRevokedBefore(userID string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.revokedBefore[userID], nil
+}
+
+// singleUserRepository knows one user; the methods it does not override are
+// not used by the tests.
+type singleUserRepository struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (r *singleUserRepository) GetByID(id string) (*domain.User, error) {
+	if id != r.user.ID.String() {
+		return nil, domain.NewNotFoundError("user not found")
+	}
+	return r.user, nil
+}
+
+func newTestAuthUseCase() (*AuthUseCase, *domain.User) {
+	user := &domain.User{ID: uuid.New(), Username: "alice"}
+	auth := NewAuthUseCase(&singleUserRepository{user: user}, newMemoryTokenRepository(), testJWTSecret, time.Hour, 24*time.Hour)
+	return auth, user
+}
+
+func TestLogoutAllRevokesTokensIssuedInTheSameSecond(t *testing.T) {
+	auth, user := newTestAuthUseCase()
+
+	// Issue right after a second starts, so the logout below almost surely
+	// lands in the same second; it must win either way
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	issued, err := auth.issueTokens(user, uuid.NewString())
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+	if err := auth.LogoutAll(user.ID.String()); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+
+	claims, err := middleware.ValidateToken(issued.Token, testJWTSecret)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	revoked, err := auth.IsTokenRevoked(claims)
+	if err != nil {
+		t.Fatalf("IsTokenRevoked: %v", err)
+	}
+	if !revoked {
+		t.Error("access token issued in the same second as logout-all is still accepted")
+	}
+
+	if _, err := auth.Refresh(&RefreshRequest{RefreshToken: issued.RefreshToken}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh with a token issued before logout-all returned %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestLogoutAllKeepsRefreshTokensIssuedAfterIt(t *testing.T) {
+	auth, user := newTestAuthUseCase()
+
+	if err := auth.LogoutAll(user.ID.String()); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+	issued, err := auth.issueTokens(user, uuid.NewString())
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+
+	if _, err := auth.Refresh(&RefreshRequest{RefreshToken: issued.RefreshToken}); err != nil {
+		t.Errorf("Refresh with a token issued after logout-all returned %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"socialnetwork/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type blockUseCase struct {
+	blockRepo domain.BlockRepository
+	muteRepo  domain.MuteRepository
+	userRepo  domain.UserRepository
+	mediaRepo domain.MediaRepository
+	feeds     domain.FeedCache
+}
+
+func NewBlockUseCase(blockRepo domain.BlockRepository, muteRepo domain.MuteRepository, userRepo domain.UserRepository, mediaRepo domain.MediaRepository, feeds domain.FeedCache) domain.BlockUseCase {
+	return &blockUseCase{
+		blockRepo: blockRepo,
+		muteRepo:  muteRepo,
+		userRepo:  userRepo,
+		mediaRepo: mediaRepo,
+		feeds:     feeds,
+	}
+}
+
+func (u *blockUseCase) Block(blockerID string, blockedID string) error {
+	blockerUID, blockedUID, err := u.parsePair(blockerID, blockedID, "block")
+	if err != nil {
+		return err
+	}
+	if err := u.blockRepo.Create(&domain.Block{
+		BlockerID: blockerUID,
+		BlockedID: blockedUID,
+	}); err != nil {
+		return err
+	}
+	u.feeds.InvalidateViewers(blockerID, blockedID)
+	return nil
+}
+
+func (u *blockUseCase) Unblock(blockerID string, blockedID string) error {
+	if blockerID == "" || blockedID == "" {
+		return domain.NewValidationError("invalid user id")
+	}
+	if err := u.blockRepo.Delete(blockerID, blockedID); err != nil {
+		return err
+	}
+	u.feeds.InvalidateViewers(blockerID, blockedID)
+	return nil
+}
+
+func (u *blockUseCase) GetBlocked(userID string, page int, limit int) ([]*domain.PublicUser, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	users, err := u.blockRepo.GetBlocked(userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+func (u *blockUseCase) Mute(muterID string, mutedID string) error {
+	muterUID, mutedUID, err := u.parsePair(muterID, mutedID, "mute")
+	if err != nil {
+		return err
+	}
+	if err := u.muteRepo.Create(&domain.Mute{
+		MuterID: muterUID,
+		MutedID: mutedUID,
+	}); err != nil {
+		return err
+	}
+	u.feeds.InvalidateViewers(muterID)
+	return nil
+}
+
+func (u *blockUseCase) Unmute(muterID string, mutedID string) error {
+	if muterID == "" || mutedID == "" {
+		return domain.NewValidationError("invalid user id")
+	}
+	if err := u.muteRepo.Delete(muterID, mutedID); err != nil {
+		return err
+	}
+	u.feeds.InvalidateViewers(muterID)
+	return nil
+}
+
+func (u *blockUseCase) GetMuted(userID string, page int, limit int) ([]*domain.PublicUser, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	users, err := u.muteRepo.GetMuted(userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+// parsePair checks that a user may block or mute the target: both ids must
+// be valid, differ, and the target must exist.
+func (u *blockUseCase) parsePair(userID string, targetID string, action string) (uuid.UUID, uuid.UUID, error) {
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, domain.NewValidationError("invalid user id")
+	}
+	targetUID, err := uuid.Parse(targetID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, domain.NewValidationError("invalid user id")
+	}
+	if userUID == targetUID {
+		return uuid.Nil, uuid.Nil, domain.NewValidationError("cannot " + action + " yourself")
+	}
+	if _, err := u.userRepo.GetByID(targetID); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return userUID, targetUID, nil
+}
+
+// checkNotBlocked fails with a not found error when either user blocked the
+// other, so that neither can tell the other apart from a missing one.
+func checkNotBlocked(blockRepo domain.BlockRepository, a uuid.UUID, b uuid.UUID, notFoundMessage string) error {
+	blocked, err := blockRepo.IsBlocked(a, b)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return domain.NewNotFoundError(notFoundMessage)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type commentUseCase struct {
+	commentRepo domain.CommentRepository
+	postRepo    domain.PostRepository
+	userRepo    domain.UserRepository
+	mentionRepo domain.MentionRepository
+	blockRepo   domain.BlockRepository
+	events      domain.EventPublisher
+}
+
+func NewCommentUseCase(commentRepo domain.CommentRepository, postRepo domain.PostRepository, userRepo domain.UserRepository, mentionRepo domain.MentionRepository, blockRepo domain.BlockRepository, events domain.EventPublisher) domain.CommentUseCase {
+	return &commentUseCase{
+		commentRepo: commentRepo,
+		postRepo:    postRepo,
+		userRepo:    userRepo,
+		mentionRepo: mentionRepo,
+		blockRepo:   blockRepo,
+		events:      events,
+	}
+}
+
+func (u *commentUseCase) GetComments(viewerID string, postID string, query domain.PageQuery) (*domain.CommentPage, error) {
+	if _, err := u.postRepo.GetByID(viewerID, postID); err != nil {
+		return nil, err
+	}
+
+	query = normalizePageQuery(query)
+	roots, err := u.commentRepo.GetRootsByPostID(viewerID, postID, probe(query))
+	if err != nil {
+		return nil, err
+	}
+
+	roots, next, prev := trimPage(roots, query, func(comment *domain.Comment) (time.Time, uuid.UUID) {
+		return comment.CreatedAt, comment.ID
+	})
+
+	rootIDs := make([]uuid.UUID, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = root.ID
+	}
+	replies, err := u.commentRepo.GetDescendants(viewerID, rootIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachCommentMentions(u.mentionRepo, slices.Concat(roots, replies)...); err != nil {
+		return nil, err
+	}
+
+	return &domain.CommentPage{
+		Comments:   buildCommentTree(roots, replies),
+		NextCursor: next,
+		PrevCursor: prev,
+	}, nil
+}
+
+func (u *commentUseCase) CreateComment(comment *domain.Comment) error {
+	if comment.UserID == uuid.Nil || comment.Content == "" {
+		return domain.NewValidationError("user id and content are required")
+	}
+	if len(comment.Content) > domain.MaxCommentLength {
+		return domain.NewValidationError("comment is too long")
+	}
+
+	post, err := u.postRepo.GetByID(comment.UserID.String(), comment.PostID.String())
+	if err != nil {
+		return err
+	}
+
+	// Replies must hang off a live comment of the same post, by someone the
+	// author may interact with
+	if comment.ParentID != nil {
+		parent, err := u.commentRepo.GetByID(comment.ParentID.String())
+		if err == nil {
+			err = checkNotBlocked(u.blockRepo, comment.UserID, parent.UserID, "parent comment not found")
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NewValidationError("parent comment not found")
+		}
+		if err != nil {
+			return err
+		}
+		if parent.PostID != comment.PostID {
+			return domain.NewValidationError("parent comment belongs to another post")
+		}
+	}
+
+	if err := u.commentRepo.Create(comment); err != nil {
+		return err
+	}
+	if err := u.setMentions(comment); err != nil {
+		return err
+	}
+
+	u.events.Publish(domain.Event{
+		Type:      domain.EventCommentCreated,
+		ActorID:   comment.UserID,
+		UserID:    post.UserID,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	})
+	return nil
+}
+
+func (u *commentUseCase) UpdateComment(comment *domain.Comment) error {
+	if comment.ID == uuid.Nil {
+		return domain.NewValidationError("invalid comment id")
+	}
+	if comment.Content == "" {
+		return domain.NewValidationError("content is required")
+	}
+	if len(comment.Content) > domain.MaxCommentLength {
+		return domain.NewValidationError("comment is too long")
+	}
+
+	existingComment, err := u.commentRepo.GetByID(comment.ID.String())
+	if err != nil {
+		return err
+	}
+
+	// Only the author can edit a comment
+	if existingComment.UserID != comment.UserID {
+		return domain.NewForbiddenError("unauthorized to update this comment")
+	}
+
+	existingComment.Content = comment.Content
+	if err := u.commentRepo.Update(existingComment); err != nil {
+		return err
+	}
+	if err := u.setMentions(existingComment); err != nil {
+		return err
+	}
+
+	*comment = *existingComment
+	return nil
+}
+
+func (u *commentUseCase) DeleteComment(userID string, commentID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.NewValidationError("invalid user id")
+	}
+
+	comment, err := u.commentRepo.GetByID(commentID)
+	if err != nil {
+		return err
+	}
+
+	// The author may delete their comment, and the post owner may moderate
+	// anything posted under their post
+	if comment.UserID != uid {
+		post, err := u.postRepo.GetByID("", comment.PostID.String())
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		if post == nil || post.UserID != uid {
+			return domain.NewForbiddenError("unauthorized to delete this comment")
+		}
+	}
+
+	return u.commentRepo.Delete(commentID)
+}
+
+// setMentions records the users the comment mentions and where, and lets the
+// newly mentioned know.
+func (u *commentUseCase) setMentions(comment *domain.Comment) error {
+	users, err := resolveMentions(u.userRepo, comment.Content)
+	if err != nil {
+		return err
+	}
+	added, err := u.mentionRepo.SetCommentMentions(comment, userIDs(users))
+	if err != nil {
+		return err
+	}
+	comment.Mentions = domain.MentionEntities(comment.Content, users)
+
+	for _, userID := range added {
+		u.events.Publish(domain.Event{
+			Type:      domain.EventUserMentioned,
+			ActorID:   comment.UserID,
+			UserID:    userID,
+			PostID:    comment.PostID,
+			CommentID: comment.ID,
+		})
+	}
+	return nil
+}
+
+// buildCommentTree nests replies under their parents. Deleted comments keep
+// their place in the thread with their content removed, unless nothing below
+// them is left to show.
+func buildCommentTree(roots []*domain.Comment, replies []*domain.Comment) []*domain.Comment {
+	byID := make(map[uuid.UUID]*domain.Comment, len(roots)+len(replies))
+	for _, root := range roots {
+		byID[root.ID] = root
+	}
+	for _, reply := range replies {
+		byID[reply.ID] = reply
+	}
+	for _, reply := range replies {
+		if parent, ok := byID[*reply.ParentID]; ok {
+			parent.Replies = append(parent.Replies, reply)
+		}
+	}
+	return pruneDeleted(roots)
+}
+
+func pruneDeleted(comments []*domain.Comment) []*domain.Comment {
+	kept := make([]*domain.Comment, 0, len(comments))
+	for _, comment := range comments {
+		comment.Replies = pruneDeleted(comment.Replies)
+		if comment.DeletedAt != nil {
+			if len(comment.Replies) == 0 {
+				continue
+			}
+			comment.Content = ""
+		}
+		kept = append(kept, comment)
+	}
+	return kept
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"socialnetwork/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type conversationUseCase struct {
+	conversationRepo domain.ConversationRepository
+	messageRepo      domain.MessageRepository
+	userRepo         domain.UserRepository
+	mediaRepo        domain.MediaRepository
+	blockRepo        domain.BlockRepository
+	events           domain.EventPublisher
+}
+
+func NewConversationUseCase(conversationRepo domain.ConversationRepository, messageRepo domain.MessageRepository, userRepo domain.UserRepository, mediaRepo domain.MediaRepository, blockRepo domain.BlockRepository, events domain.EventPublisher) domain.ConversationUseCase {
+	return &conversationUseCase{
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		userRepo:         userRepo,
+		mediaRepo:        mediaRepo,
+		blockRepo:        blockRepo,
+		events:           events,
+	}
+}
+
+// StartConversation opens a conversation between the user and the others
+// named in req. Starting an untitled conversation with one other user returns
+// the thread the two already share, if any.
+func (u *conversationUseCase) StartConversation(userID string, req *domain.StartConversationRequest) (*domain.Conversation, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	participantIDs := []uuid.UUID{uid}
+	for _, id := range req.ParticipantIDs {
+		pid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, domain.NewValidationError("invalid participant id " + id)
+		}
+		if !slices.Contains(participantIDs, pid) {
+			participantIDs = append(participantIDs, pid)
+		}
+	}
+	if len(participantIDs) < 2 {
+		return nil, domain.NewValidationError("a conversation needs another participant")
+	}
+	if len(participantIDs) > domain.MaxConversationParticipants {
+		return nil, domain.NewValidationError("too many participants")
+	}
+	title := strings.TrimSpace(req.Title)
+	if len(title) > 100 {
+		return nil, domain.NewValidationError("title is too long")
+	}
+
+	// Every participant must exist and be someone the user may talk to
+	for _, pid := range participantIDs[1:] {
+		if _, err := u.userRepo.GetByID(pid.String()); err != nil {
+			return nil, err
+		}
+		if err := checkNotBlocked(u.blockRepo, uid, pid, "user not found"); err != nil {
+			return nil, err
+		}
+	}
+
+	conversation := &domain.Conversation{Title: title, CreatedBy: uid}
+	if len(participantIDs) == 2 && title == "" {
+		key := directKey(participantIDs[0], participantIDs[1])
+		existing, err := u.conversationRepo.GetByDirectKey(key)
+		if err == nil {
+			return existing, u.decorate(uid, existing)
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		conversation.DirectKey = &key
+	}
+
+	err = u.conversationRepo.Create(conversation, participantIDs)
+	if errors.Is(err, domain.ErrConflict) && conversation.DirectKey != nil {
+		// The other user started the same conversation at the same time
+		conversation, err = u.conversationRepo.GetByDirectKey(*conversation.DirectKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conversation, u.decorate(uid, conversation)
+}
+
+func (u *conversationUseCase) GetConversation(userID string, id string) (*domain.Conversation, error) {
+	uid, conversation, err := u.authorize(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return conversation, u.decorate(uid, conversation)
+}
+
+func (u *conversationUseCase) GetConversations(userID string, page int, limit int) ([]*domain.Conversation, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	conversations, err := u.conversationRepo.GetByUserID(userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return conversations, u.decorate(uid, conversations...)
+}
+
+func (u *conversationUseCase) GetMessages(userID string, conversationID string, query domain.PageQuery) (*domain.MessagePage, error) {
+	_, conversation, err := u.authorize(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = normalizePageQuery(query)
+	messages, err := u.messageRepo.GetByConversationID(conversation.ID, probe(query))
+	if err != nil {
+		return nil, err
+	}
+	messages, next, prev := trimPage(messages, query, func(message *domain.Message) (time.Time, uuid.UUID) {
+		return message.CreatedAt, message.ID
+	})
+	return &domain.MessagePage{Messages: messages, NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (u *conversationUseCase) SendMessage(userID string, conversationID string, req *domain.SendMessageRequest) (*domain.Message, error) {
+	uid, conversation, err := u.authorize(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, domain.NewValidationError("content is required")
+	}
+	if len(content) > domain.MaxMessageLength {
+		return nil, domain.NewValidationError("message is too long")
+	}
+	if err := u.checkCanMessage(uid, conversation); err != nil {
+		return nil, err
+	}
+
+	message := &domain.Message{ConversationID: conversation.ID, SenderID: uid, Content: content}
+	if err := u.messageRepo.Create(message); err != nil {
+		return nil, err
+	}
+	// Senders have read their own conversation up to their message
+	if err := u.conversationRepo.MarkRead(conversation.ID, uid, message.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	published := *message
+	u.events.Publish(domain.Event{
+		Type:    domain.EventMessageCreated,
+		ActorID: uid,
+		Message: &published,
+	})
+	return message, nil
+}
+
+func (u *conversationUseCase) MarkRead(userID string, conversationID string) error {
+	uid, conversation, err := u.authorize(userID, conversationID)
+	if err != nil {
+		return err
+	}
+	return u.conversationRepo.MarkRead(conversation.ID, uid, time.Now())
+}
+
+func (u *conversationUseCase) CountUnread(userID string) (int64, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return 0, domain.NewValidationError("invalid user id")
+	}
+	return u.conversationRepo.CountAllUnread(uid)
+}
+
+// authorize loads the conversation if the user takes part in it. Others are
+// told it does not exist, so they cannot probe for conversations.
+func (u *conversationUseCase) authorize(userID string, conversationID string) (uuid.UUID, *domain.Conversation, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, nil, domain.NewValidationError("invalid user id")
+	}
+
+	conversation, err := u.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	ok, err := u.conversationRepo.IsParticipant(conversation.ID, uid)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	if !ok {
+		return uuid.Nil, nil, domain.NewNotFoundError("conversation not found")
+	}
+	return uid, conversation, nil
+}
+
+// checkCanMessage stops messages between users who blocked each other. Group
+// conversations carry on, as one block would otherwise silence everyone.
+func (u *conversationUseCase) checkCanMessage(senderID uuid.UUID, conversation *domain.Conversation) error {
+	if conversation.DirectKey == nil {
+		return nil
+	}
+	participantIDs, err := u.conversationRepo.GetParticipantIDs(conversation.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range participantIDs {
+		if id == senderID {
+			continue
+		}
+		blocked, err := u.blockRepo.IsBlocked(senderID, id)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return domain.NewForbiddenError("cannot message this user")
+		}
+	}
+	return nil
+}
+
+// decorate fills in the participants, last message and unread count of each
+// conversation for the user.
+func (u *conversationUseCase) decorate(userID uuid.UUID, conversations ...*domain.Conversation) error {
+	ids := make([]uuid.UUID, len(conversations))
+	for i, conversation := range conversations {
+		ids[i] = conversation.ID
+	}
+
+	participants, err := u.conversationRepo.GetParticipants(ids)
+	if err != nil {
+		return err
+	}
+	var users []*domain.User
+	for _, list := range participants {
+		users = append(users, list...)
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return err
+	}
+
+	latest, err := u.messageRepo.GetLatest(ids)
+	if err != nil {
+		return err
+	}
+	unread, err := u.conversationRepo.CountUnread(userID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, conversation := range conversations {
+		conversation.Participants = domain.NewPublicUsers(participants[conversation.ID])
+		conversation.LastMessage = latest[conversation.ID]
+		conversation.UnreadCount = unread[conversation.ID]
+	}
+	return nil
+}
+
+// directKey identifies the one-to-one conversation of two users regardless
+// of who started it.
+func directKey(a uuid.UUID, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
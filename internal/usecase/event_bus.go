@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"socialnetwork/internal/domain"
+	"sync"
+	"time"
+)
+
+// EventBus delivers published events to its subscribers in the background,
+// one at a time and in publishing order.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []func(event domain.Event)
+	events   chan domain.Event
+}
+
+func NewEventBus(queueSize int) *EventBus {
+	return &EventBus{events: make(chan domain.Event, queueSize)}
+}
+
+// Subscribe registers handler for every event published from now on.
+func (b *EventBus) Subscribe(handler func(event domain.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish queues event without blocking. When the queue is full the event is
+// dropped, since losing a notification beats stalling a request.
+func (b *EventBus) Publish(event domain.Event) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	select {
+	case b.events <- event:
+	default:
+		log.Printf("events: queue full, dropping %s", event.Type)
+	}
+}
+
+// Run delivers queued events until ctx is done.
+func (b *EventBus) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-b.events:
+			b.mu.RLock()
+			handlers := b.handlers
+			b.mu.RUnlock()
+			for _, handler := range handlers {
+				handler(event)
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"socialnetwork/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type followUseCase struct {
+	followRepo domain.FollowRepository
+	userRepo   domain.UserRepository
+	mediaRepo  domain.MediaRepository
+	blockRepo  domain.BlockRepository
+	feeds      domain.FeedCache
+	events     domain.EventPublisher
+}
+
+func NewFollowUseCase(followRepo domain.FollowRepository, userRepo domain.UserRepository, mediaRepo domain.MediaRepository, blockRepo domain.BlockRepository, feeds domain.FeedCache, events domain.EventPublisher) domain.FollowUseCase {
+	return &followUseCase{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+		mediaRepo:  mediaRepo,
+		blockRepo:  blockRepo,
+		feeds:      feeds,
+		events:     events,
+	}
+}
+
+// Follow follows the user straight away, unless their account is private, in
+// which case it asks them for approval instead.
+func (u *followUseCase) Follow(followerID string, followeeID string) (domain.FollowStatus, error) {
+	followerUID, err := uuid.Parse(followerID)
+	if err != nil {
+		return "", domain.NewValidationError("invalid user id")
+	}
+	followeeUID, err := uuid.Parse(followeeID)
+	if err != nil {
+		return "", domain.NewValidationError("invalid user id")
+	}
+	if followerUID == followeeUID {
+		return "", domain.NewValidationError("cannot follow yourself")
+	}
+
+	followee, err := u.userRepo.GetByID(followeeID)
+	if err != nil {
+		return "", err
+	}
+	if err := checkNotBlocked(u.blockRepo, followerUID, followeeUID, "user not found"); err != nil {
+		return "", err
+	}
+
+	if followee.IsPrivate {
+		following, err := u.followRepo.IsFollowing(followerUID, followeeUID)
+		if err != nil {
+			return "", err
+		}
+		if !following {
+			return u.requestFollow(followerUID, followeeUID)
+		}
+	}
+
+	created, err := u.followRepo.Create(&domain.Follow{
+		FollowerID: followerUID,
+		FolloweeID: followeeUID,
+	})
+	if err != nil {
+		return "", err
+	}
+	// Following again changes nothing, so nobody is notified twice
+	if !created {
+		return domain.FollowStatusFollowing, nil
+	}
+	u.feeds.InvalidateViewers(followerID)
+
+	u.events.Publish(domain.Event{
+		Type:    domain.EventUserFollowed,
+		ActorID: followerUID,
+		UserID:  followeeUID,
+	})
+	return domain.FollowStatusFollowing, nil
+}
+
+func (u *followUseCase) requestFollow(followerUID uuid.UUID, followeeUID uuid.UUID) (domain.FollowStatus, error) {
+	created, err := u.followRepo.CreateRequest(&domain.FollowRequest{
+		FollowerID: followerUID,
+		FolloweeID: followeeUID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if !created {
+		return domain.FollowStatusRequested, nil
+	}
+
+	u.events.Publish(domain.Event{
+		Type:    domain.EventFollowRequested,
+		ActorID: followerUID,
+		UserID:  followeeUID,
+	})
+	return domain.FollowStatusRequested, nil
+}
+
+// Unfollow ends the follow, or withdraws the request to follow.
+func (u *followUseCase) Unfollow(followerID string, followeeID string) error {
+	if followerID == "" || followeeID == "" {
+		return domain.NewValidationError("invalid user id")
+	}
+	if err := u.followRepo.DeleteRequest(followerID, followeeID); err != nil {
+		return err
+	}
+	if err := u.followRepo.Delete(followerID, followeeID); err != nil {
+		return err
+	}
+	u.feeds.InvalidateViewers(followerID)
+	return nil
+}
+
+func (u *followUseCase) GetFollowRequests(userID string, page int, limit int) ([]*domain.PublicUser, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	users, err := u.followRepo.GetRequests(userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+func (u *followUseCase) ApproveFollowRequest(userID string, followerID string) error {
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.NewValidationError("invalid user id")
+	}
+	followerUID, err := uuid.Parse(followerID)
+	if err != nil {
+		return domain.NewValidationError("invalid user id")
+	}
+
+	if err := u.followRepo.ApproveRequest(followerUID, userUID); err != nil {
+		return err
+	}
+	u.feeds.InvalidateViewers(followerID)
+
+	u.events.Publish(domain.Event{
+		Type:    domain.EventUserFollowed,
+		ActorID: followerUID,
+		UserID:  userUID,
+	})
+	return nil
+}
+
+func (u *followUseCase) RejectFollowRequest(userID string, followerID string) error {
+	if userID == "" || followerID == "" {
+		return domain.NewValidationError("invalid user id")
+	}
+	return u.followRepo.DeleteRequest(followerID, userID)
+}
+
+func (u *followUseCase) GetFollowers(viewerID string, userID string, page int, limit int) ([]*domain.PublicUser, error) {
+	if err := u.checkVisible(viewerID, userID); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	users, err := u.followRepo.GetFollowers(viewerID, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+func (u *followUseCase) GetFollowing(viewerID string, userID string, page int, limit int) ([]*domain.PublicUser, error) {
+	if err := u.checkVisible(viewerID, userID); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	users, err := u.followRepo.GetFollowing(viewerID, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+// checkVisible hides the lists of users who blocked, or were blocked by, the
+// viewer. Those of private accounts are only shown to their followers.
+func (u *followUseCase) checkVisible(viewerID string, userID string) error {
+	viewerUID, err := uuid.Parse(viewerID)
+	if err != nil {
+		return domain.NewValidationError("invalid user id")
+	}
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == viewerUID {
+		return nil
+	}
+	if err := checkNotBlocked(u.blockRepo, viewerUID, user.ID, "user not found"); err != nil {
+		return err
+	}
+
+	if user.IsPrivate {
+		following, err := u.followRepo.IsFollowing(viewerUID, user.ID)
+		if err != nil {
+			return err
+		}
+		if !following {
+			return domain.NewForbiddenError("this account is private")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"socialnetwork/internal/domain"
+	"time"
+)
+
+const (
+	defaultTrendingLimit = 10
+	maxTrendingLimit     = 50
+)
+
+type hashtagUseCase struct {
+	hashtagRepo domain.HashtagRepository
+}
+
+func NewHashtagUseCase(hashtagRepo domain.HashtagRepository) domain.HashtagUseCase {
+	return &hashtagUseCase{hashtagRepo: hashtagRepo}
+}
+
+// GetTrending returns the hashtags rising fastest as of the last refresh.
+func (u *hashtagUseCase) GetTrending(limit int) ([]*domain.TrendingHashtag, error) {
+	if limit <= 0 {
+		limit = defaultTrendingLimit
+	}
+	if limit > maxTrendingLimit {
+		limit = maxTrendingLimit
+	}
+	return u.hashtagRepo.GetTrending(limit)
+}
+
+// TrendingRefresher recomputes trending hashtags in the background.
+type TrendingRefresher struct {
+	hashtagRepo domain.HashtagRepository
+}
+
+func NewTrendingRefresher(hashtagRepo domain.HashtagRepository) *TrendingRefresher {
+	return &TrendingRefresher{hashtagRepo: hashtagRepo}
+}
+
+// Run refreshes trending hashtags immediately and then every interval until
+// ctx is done.
+func (r *TrendingRefresher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.hashtagRepo.RefreshTrending(time.Now()); err != nil {
+			log.Printf("hashtags: refreshing trends failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
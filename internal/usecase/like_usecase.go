@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"socialnetwork/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type likeUseCase struct {
+	likeRepo  domain.LikeRepository
+	postRepo  domain.PostRepository
+	mediaRepo domain.MediaRepository
+	events    domain.EventPublisher
+}
+
+func NewLikeUseCase(likeRepo domain.LikeRepository, postRepo domain.PostRepository, mediaRepo domain.MediaRepository, events domain.EventPublisher) domain.LikeUseCase {
+	return &likeUseCase{
+		likeRepo:  likeRepo,
+		postRepo:  postRepo,
+		mediaRepo: mediaRepo,
+		events:    events,
+	}
+}
+
+func (u *likeUseCase) LikePost(userID string, postID string) error {
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.NewValidationError("invalid user id")
+	}
+
+	// Only live posts the user can see can be liked
+	post, err := u.postRepo.GetByID(userID, postID)
+	if err != nil {
+		return err
+	}
+
+	created, err := u.likeRepo.Create(&domain.PostLike{
+		PostID: post.ID,
+		UserID: userUID,
+	})
+	if err != nil {
+		return err
+	}
+	// Liking again changes nothing, so nobody is notified twice
+	if !created {
+		return nil
+	}
+
+	u.events.Publish(domain.Event{
+		Type:    domain.EventPostLiked,
+		ActorID: userUID,
+		UserID:  post.UserID,
+		PostID:  post.ID,
+	})
+	return nil
+}
+
+func (u *likeUseCase) UnlikePost(userID string, postID string) error {
+	if userID == "" || postID == "" {
+		return domain.NewValidationError("invalid post id")
+	}
+	return u.likeRepo.Delete(postID, userID)
+}
+
+func (u *likeUseCase) GetLikers(viewerID string, postID string, page int, limit int) ([]*domain.PublicUser, error) {
+	if postID == "" {
+		return nil, domain.NewValidationError("invalid post id")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	// Likes are listed only on posts the viewer can see
+	post, err := u.postRepo.GetByID(viewerID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := u.likeRepo.GetLikers(viewerID, post.ID.String(), page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
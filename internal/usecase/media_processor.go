@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"socialnetwork/internal/domain"
+	"socialnetwork/pkg/imaging"
+	"sync"
+	"time"
+)
+
+const (
+	// mediaSweepInterval is how often media left processing, because the
+	// queue was full or the server restarted, is queued again.
+	mediaSweepInterval = time.Minute
+	mediaStaleAfter    = 2 * time.Minute
+)
+
+// imageVariantSizes are the longest edge, in pixels, of each generated size.
+var imageVariantSizes = []struct {
+	name    string
+	maxEdge int
+	set     func(media *domain.Media, url string)
+}{
+	{"thumbnail", 160, func(media *domain.Media, url string) { media.ThumbnailURL = url }},
+	{"medium", 640, func(media *domain.Media, url string) { media.MediumURL = url }},
+}
+
+// MediaProcessor generates the smaller sizes of uploaded images in the
+// background.
+type MediaProcessor struct {
+	mediaRepo domain.MediaRepository
+	storage   domain.MediaStorage
+	jobs      chan *domain.Media
+}
+
+func NewMediaProcessor(mediaRepo domain.MediaRepository, storage domain.MediaStorage, queueSize int) *MediaProcessor {
+	return &MediaProcessor{
+		mediaRepo: mediaRepo,
+		storage:   storage,
+		jobs:      make(chan *domain.Media, queueSize),
+	}
+}
+
+// Enqueue schedules a copy of media for processing without blocking. When
+// the queue is full the media stays processing until the next sweep picks it
+// up.
+func (p *MediaProcessor) Enqueue(media *domain.Media) {
+	job := *media
+	select {
+	case p.jobs <- &job:
+	default:
+		log.Printf("media: queue full, deferring %s", media.ID)
+	}
+}
+
+// Run processes queued media with the given number of workers until ctx is
+// done.
+func (p *MediaProcessor) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case media := <-p.jobs:
+					p.process(media)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(mediaSweepInterval)
+	defer ticker.Stop()
+	for {
+		p.sweep()
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *MediaProcessor) sweep() {
+	stale, err := p.mediaRepo.GetStaleProcessing(time.Now().Add(-mediaStaleAfter))
+	if err != nil {
+		log.Printf("media: sweep failed: %v", err)
+		return
+	}
+	for _, media := range stale {
+		p.Enqueue(media)
+	}
+}
+
+// process generates every size of media and records the result. Processing
+// is idempotent, so media queued twice is harmless.
+func (p *MediaProcessor) process(media *domain.Media) {
+	if err := p.generateVariants(media); err != nil {
+		log.Printf("media: processing %s failed: %v", media.ID, err)
+		media.Status = domain.MediaFailed
+	} else {
+		media.Status = domain.MediaReady
+	}
+
+	if err := p.mediaRepo.UpdateVariants(media); err != nil {
+		log.Printf("media: saving %s failed: %v", media.ID, err)
+	}
+}
+
+func (p *MediaProcessor) generateVariants(media *domain.Media) error {
+	data, err := p.storage.Get(media.StorageKey)
+	if err != nil {
+		return err
+	}
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	for _, size := range imageVariantSizes {
+		body, contentType, ext, err := imaging.Encode(imaging.Fit(img, size.maxEdge), format)
+		if err != nil {
+			return err
+		}
+		key := mediaVariantKey(media.Hash, size.name, ext)
+		if err := p.storage.Put(key, body, contentType); err != nil {
+			return err
+		}
+		size.set(media, p.storage.URL(key))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"socialnetwork/internal/domain"
+	"socialnetwork/pkg/imaging"
+
+	"github.com/google/uuid"
+)
+
+// allowedMediaTypes maps the sniffed content types accepted for upload to the
+// file extension they are stored with.
+var allowedMediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+}
+
+// processedMediaTypes are re-encoded on upload and resized in the
+// background. GIFs are kept as uploaded so animations survive.
+var processedMediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+type mediaUseCase struct {
+	mediaRepo domain.MediaRepository
+	storage   domain.MediaStorage
+	processor *MediaProcessor
+	maxSize   int64
+}
+
+func NewMediaUseCase(mediaRepo domain.MediaRepository, storage domain.MediaStorage, processor *MediaProcessor, maxSize int64) domain.MediaUseCase {
+	return &mediaUseCase{
+		mediaRepo: mediaRepo,
+		storage:   storage,
+		processor: processor,
+		maxSize:   maxSize,
+	}
+}
+
+// Upload stores file for the user. The content type is sniffed from the data
+// rather than trusted from the client, and content that was uploaded before
+// is not stored again. Images are re-encoded before they are stored, which
+// strips their metadata; their smaller sizes are generated in the background.
+func (u *mediaUseCase) Upload(userID string, file io.Reader) (*domain.Media, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(file, u.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, domain.NewValidationError("file is empty")
+	}
+	if int64(len(body)) > u.maxSize {
+		return nil, domain.NewValidationError(fmt.Sprintf("file exceeds %d bytes", u.maxSize))
+	}
+
+	contentType := http.DetectContentType(body)
+	ext, ok := allowedMediaTypes[contentType]
+	if !ok {
+		return nil, domain.NewValidationError("unsupported file type " + contentType)
+	}
+
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+
+	existing, err := u.mediaRepo.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	for _, media := range existing {
+		if media.UserID == uid {
+			return media, nil
+		}
+	}
+
+	var media *domain.Media
+	if len(existing) > 0 {
+		// Share the files stored for the first upload of this content
+		media = existing[0]
+		media.ID = uuid.Nil
+		media.UserID = uid
+	} else {
+		media, err = u.store(uid, hash, body, contentType, ext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.mediaRepo.Create(media); err != nil {
+		return nil, err
+	}
+	if media.Status == domain.MediaProcessing {
+		u.processor.Enqueue(media)
+	}
+	return media, nil
+}
+
+func (u *mediaUseCase) store(userID uuid.UUID, hash string, body []byte, contentType string, ext string) (*domain.Media, error) {
+	media := &domain.Media{
+		UserID:      userID,
+		Hash:        hash,
+		ContentType: contentType,
+		Status:      domain.MediaReady,
+		StorageKey:  hash[:2] + "/" + hash + ext,
+	}
+
+	if processedMediaTypes[contentType] {
+		img, format, err := imaging.Decode(body)
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return nil, domain.NewValidationError(err.Error())
+		}
+		if err != nil {
+			return nil, domain.NewValidationError("file is not a valid image")
+		}
+		if body, media.ContentType, ext, err = imaging.Encode(img, format); err != nil {
+			return nil, err
+		}
+		media.StorageKey = mediaVariantKey(hash, "original", ext)
+		media.Status = domain.MediaProcessing
+	}
+
+	if err := u.storage.Put(media.StorageKey, body, media.ContentType); err != nil {
+		return nil, err
+	}
+	media.Size = int64(len(body))
+	media.URL = u.storage.URL(media.StorageKey)
+	return media, nil
+}
+
+func mediaVariantKey(hash string, variant string, ext string) string {
+	return hash[:2] + "/" + hash + "/" + variant + ext
+}
+
+// resolveVariants maps each of urls that refers to an upload to the URLs of
+// its sizes.
+func resolveVariants(mediaRepo domain.MediaRepository, urls []string) (map[string]*domain.ImageVariants, error) {
+	variants := make(map[string]*domain.ImageVariants, len(urls))
+	if len(urls) == 0 {
+		return variants, nil
+	}
+
+	media, err := mediaRepo.GetByURLs(urls)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range media {
+		variants[m.URL] = m.Variants()
+	}
+	return variants, nil
+}
+
+// attachAvatars fills in the sized versions of each user's avatar when it is
+// an upload.
+func attachAvatars(mediaRepo domain.MediaRepository, users ...*domain.User) error {
+	urls := make([]string, 0, len(users))
+	for _, user := range users {
+		if user.Avatar != "" {
+			urls = append(urls, user.Avatar)
+		}
+	}
+	variants, err := resolveVariants(mediaRepo, urls)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		user.AvatarVariants = variants[user.Avatar]
+	}
+	return nil
+}
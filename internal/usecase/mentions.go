@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"socialnetwork/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// resolveMentions looks up the users mentioned in content. Mentions of
+// usernames nobody has are ignored.
+func resolveMentions(userRepo domain.UserRepository, content string) ([]*domain.User, error) {
+	return userRepo.GetByUsernames(domain.ParseMentions(content))
+}
+
+func userIDs(users []*domain.User) []uuid.UUID {
+	ids := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+// attachCommentMentions fills in where each comment mentions users.
+func attachCommentMentions(mentionRepo domain.MentionRepository, comments ...*domain.Comment) error {
+	ids := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	mentioned, err := mentionRepo.GetCommentMentions(ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.Mentions = domain.MentionEntities(comment.Content, mentioned[comment.ID])
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"log"
+	"slices"
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// notificationActorLimit is how many of a notification's actors are listed
+// by name; the rest are only counted.
+const notificationActorLimit = 3
+
+type notificationUseCase struct {
+	notificationRepo domain.NotificationRepository
+	mediaRepo        domain.MediaRepository
+}
+
+func NewNotificationUseCase(notificationRepo domain.NotificationRepository, mediaRepo domain.MediaRepository) domain.NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		mediaRepo:        mediaRepo,
+	}
+}
+
+func (u *notificationUseCase) GetNotifications(userID string, query domain.PageQuery, unreadOnly bool) (*domain.NotificationPage, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	query = normalizePageQuery(query)
+	notifications, err := u.notificationRepo.GetByUserID(userID, probe(query), unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	notifications, next, prev := trimPage(notifications, query, func(n *domain.Notification) (time.Time, uuid.UUID) {
+		return n.CreatedAt, n.ID
+	})
+
+	ids := make([]uuid.UUID, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.ID
+	}
+	actors, err := u.notificationRepo.GetActors(ids, notificationActorLimit)
+	if err != nil {
+		return nil, err
+	}
+	var users []*domain.User
+	for _, list := range actors {
+		users = append(users, list...)
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	for _, n := range notifications {
+		n.Actors = domain.NewPublicUsers(actors[n.ID])
+	}
+
+	return &domain.NotificationPage{Notifications: notifications, NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (u *notificationUseCase) CountUnread(userID string) (int64, error) {
+	return u.notificationRepo.CountUnread(userID)
+}
+
+func (u *notificationUseCase) MarkRead(userID string, id string) error {
+	return u.notificationRepo.MarkRead(userID, id)
+}
+
+func (u *notificationUseCase) MarkAllRead(userID string) error {
+	return u.notificationRepo.MarkAllRead(userID)
+}
+
+func (u *notificationUseCase) GetPreferences(userID string) (domain.NotificationPreferences, error) {
+	disabled, err := u.notificationRepo.GetDisabledTypes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make(domain.NotificationPreferences, len(domain.NotificationTypes))
+	for _, t := range domain.NotificationTypes {
+		prefs[t] = !slices.Contains(disabled, t)
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences changes the types listed in prefs and leaves the others
+// as they are.
+func (u *notificationUseCase) UpdatePreferences(userID string, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	for t := range prefs {
+		if !t.IsValid() {
+			return nil, domain.NewValidationError("unknown notification type " + string(t))
+		}
+	}
+	for t, enabled := range prefs {
+		if err := u.notificationRepo.SetPreference(userID, t, enabled); err != nil {
+			return nil, err
+		}
+	}
+	return u.GetPreferences(userID)
+}
+
+// Notifier turns events into notifications for the users they concern, and
+// announces each notification it records as another event.
+type Notifier struct {
+	notificationRepo domain.NotificationRepository
+	blockRepo        domain.BlockRepository
+	postRepo         domain.PostRepository
+	events           domain.EventPublisher
+}
+
+func NewNotifier(notificationRepo domain.NotificationRepository, blockRepo domain.BlockRepository, postRepo domain.PostRepository, events domain.EventPublisher) *Notifier {
+	return &Notifier{
+		notificationRepo: notificationRepo,
+		blockRepo:        blockRepo,
+		postRepo:         postRepo,
+		events:           events,
+	}
+}
+
+// Handle records the notifications caused by event. Failures are logged, as
+// there is nobody to report them to.
+func (n *Notifier) Handle(event domain.Event) {
+	if err := n.handle(event); err != nil {
+		log.Printf("notifications: handling %s failed: %v", event.Type, err)
+	}
+}
+
+func (n *Notifier) handle(event domain.Event) error {
+	postID := optionalID(event.PostID)
+	commentID := optionalID(event.CommentID)
+
+	switch event.Type {
+	case domain.EventPostCreated:
+		// Followers only hear of the posts they can all see, and not of reposts
+		visibility := event.Post.Visibility
+		if visibility != domain.VisibilityPublic && visibility != domain.VisibilityFollowers {
+			return nil
+		}
+		if event.Post.RepostOfID != nil {
+			return nil
+		}
+		return n.notificationRepo.AddForFollowers(&domain.Notification{
+			Type:     domain.NotificationPost,
+			GroupKey: "post:" + event.PostID.String(),
+			ActorID:  event.ActorID,
+			PostID:   postID,
+		})
+	case domain.EventUserFollowed:
+		return n.notify(event, &domain.Notification{
+			Type:     domain.NotificationFollow,
+			GroupKey: "follow",
+		})
+	case domain.EventFollowRequested:
+		return n.notify(event, &domain.Notification{
+			Type:     domain.NotificationFollowRequest,
+			GroupKey: "follow_request",
+		})
+	case domain.EventPostLiked:
+		return n.notify(event, &domain.Notification{
+			Type:     domain.NotificationLike,
+			GroupKey: "like:" + event.PostID.String(),
+			PostID:   postID,
+		})
+	case domain.EventCommentCreated:
+		return n.notify(event, &domain.Notification{
+			Type:      domain.NotificationComment,
+			GroupKey:  "comment:" + event.PostID.String(),
+			PostID:    postID,
+			CommentID: commentID,
+		})
+	case domain.EventPostReposted:
+		return n.notify(event, &domain.Notification{
+			Type:     domain.NotificationRepost,
+			GroupKey: "repost:" + event.PostID.String(),
+			PostID:   postID,
+		})
+	case domain.EventPostQuoted:
+		// Quotes may be visible to fewer users than the quoted post
+		visible, err := n.postRepo.IsVisible(event.UserID.String(), event.PostID)
+		if err != nil || !visible {
+			return err
+		}
+		return n.notify(event, &domain.Notification{
+			Type:     domain.NotificationQuote,
+			GroupKey: "quote:" + event.PostID.String(),
+			PostID:   postID,
+		})
+	case domain.EventUserMentioned:
+		// Mentions in posts the user cannot see, such as private ones or
+		// comments under them, stay silent
+		visible, err := n.postRepo.IsVisible(event.UserID.String(), event.PostID)
+		if err != nil || !visible {
+			return err
+		}
+		key := "mention:" + event.PostID.String()
+		if commentID != nil {
+			key = "mention:" + event.CommentID.String()
+		}
+		return n.notify(event, &domain.Notification{
+			Type:      domain.NotificationMention,
+			GroupKey:  key,
+			PostID:    postID,
+			CommentID: commentID,
+		})
+	}
+	return nil
+}
+
+// notify sends notification to the user the event was aimed at, unless they
+// caused it themselves, switched its type off or either blocked the other.
+func (n *Notifier) notify(event domain.Event, notification *domain.Notification) error {
+	if event.UserID == event.ActorID {
+		return nil
+	}
+	blocked, err := n.blockRepo.IsBlocked(event.ActorID, event.UserID)
+	if err != nil || blocked {
+		return err
+	}
+
+	disabled, err := n.notificationRepo.GetDisabledTypes(event.UserID.String())
+	if err != nil {
+		return err
+	}
+	if slices.Contains(disabled, notification.Type) {
+		return nil
+	}
+
+	notification.UserID = event.UserID
+	notification.ActorID = event.ActorID
+	if err := n.notificationRepo.Add(notification); err != nil {
+		return err
+	}
+
+	n.events.Publish(domain.Event{
+		Type:         domain.EventNotificationCreated,
+		ActorID:      event.ActorID,
+		UserID:       event.UserID,
+		Notification: notification,
+	})
+	return nil
+}
+
+func optionalID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
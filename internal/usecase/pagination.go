@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"socialnetwork/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+func normalizePageQuery(query domain.PageQuery) domain.PageQuery {
+	if query.Limit < 1 {
+		query.Limit = defaultPageLimit
+	}
+	if query.Limit > maxPageLimit {
+		query.Limit = maxPageLimit
+	}
+	return query
+}
+
+// probe asks the repository for one extra row so the page can tell whether
+// more items exist past it.
+func probe(query domain.PageQuery) domain.PageQuery {
+	query.Limit++
+	return query
+}
+
+// trimPage drops the probe row fetched by the repository and builds the
+// cursors for the neighbouring pages. key returns the (created_at, id) pair
+// an item is ordered by.
+func trimPage[T any](items []T, query domain.PageQuery, key func(T) (time.Time, uuid.UUID)) ([]T, string, string) {
+	hasMore := len(items) > query.Limit
+	if hasMore {
+		if query.Backward() {
+			items = items[len(items)-query.Limit:]
+		} else {
+			items = items[:query.Limit]
+		}
+	}
+	if items == nil {
+		items = []T{}
+	}
+
+	if len(items) == 0 {
+		// Let clients keep polling for newer items from where they are
+		if query.Backward() {
+			return items, "", query.Cursor.Encode()
+		}
+		return items, "", ""
+	}
+
+	var next, prev string
+	// Newer items can show up at any time, so a way back is always offered
+	createdAt, id := key(items[0])
+	prev = domain.NewCursor(createdAt, id, domain.CursorPrev).Encode()
+	if hasMore || query.Backward() {
+		createdAt, id = key(items[len(items)-1])
+		next = domain.NewCursor(createdAt, id, domain.CursorNext).Encode()
+	}
+	return items, next, prev
+}
+
+func newPostPage(posts []*domain.Post, query domain.PageQuery) *domain.PostPage {
+	posts, next, prev := trimPage(posts, query, func(post *domain.Post) (time.Time, uuid.UUID) {
+		return post.CreatedAt, post.ID
+	})
+	return &domain.PostPage{Posts: posts, NextCursor: next, PrevCursor: prev}
+}
@@ -2,86 +2,666 @@ package usecase
 
 import (
 	"errors"
+	"slices"
 	"socialnetwork/internal/domain"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type postUseCase struct {
-	postRepo domain.PostRepository
-	userRepo domain.UserRepository
+	postRepo    domain.PostRepository
+	userRepo    domain.UserRepository
+	likeRepo    domain.LikeRepository
+	mediaRepo   domain.MediaRepository
+	hashtagRepo domain.HashtagRepository
+	mentionRepo domain.MentionRepository
+	commentRepo domain.CommentRepository
+	events      domain.EventPublisher
+	// editWindow is how long after publishing posts can be edited; zero
+	// means forever
+	editWindow time.Duration
 }
 
-func NewPostUseCase(postRepo domain.PostRepository, userRepo domain.UserRepository) domain.PostUseCase {
+func NewPostUseCase(postRepo domain.PostRepository, userRepo domain.UserRepository, likeRepo domain.LikeRepository, mediaRepo domain.MediaRepository, hashtagRepo domain.HashtagRepository, mentionRepo domain.MentionRepository, commentRepo domain.CommentRepository, events domain.EventPublisher, editWindow time.Duration) domain.PostUseCase {
 	return &postUseCase{
-		postRepo: postRepo,
-		userRepo: userRepo,
+		postRepo:    postRepo,
+		userRepo:    userRepo,
+		likeRepo:    likeRepo,
+		mediaRepo:   mediaRepo,
+		hashtagRepo: hashtagRepo,
+		mentionRepo: mentionRepo,
+		commentRepo: commentRepo,
+		events:      events,
+		editWindow:  editWindow,
 	}
 }
 
-func (u *postUseCase) GetPost(id string) (*domain.Post, error) {
+func (u *postUseCase) GetPost(viewerID string, id string) (*domain.Post, error) {
 	if id == "" {
-		return nil, errors.New("invalid post id")
+		return nil, domain.NewValidationError("invalid post id")
 	}
-	return u.postRepo.GetByID(id)
+
+	post, err := u.postRepo.GetByID(viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.decorate(viewerID, post); err != nil {
+		return nil, err
+	}
+	return post, nil
 }
 
-func (u *postUseCase) GetUserPosts(userID string) ([]*domain.Post, error) {
+func (u *postUseCase) GetUserPosts(viewerID string, userID string, query domain.PageQuery) (*domain.PostPage, error) {
 	if userID == "" {
-		return nil, errors.New("invalid user id")
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	query = normalizePageQuery(query)
+	posts, err := u.postRepo.GetByUserID(viewerID, userID, probe(query))
+	if err != nil {
+		return nil, err
 	}
-	return u.postRepo.GetByUserID(userID)
+
+	page := newPostPage(posts, query)
+	if err := u.decorate(viewerID, page.Posts...); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
 func (u *postUseCase) CreatePost(post *domain.Post) error {
 	if post.UserID == uuid.Nil || post.Content == "" {
-		return errors.New("user id and content are required")
+		return domain.NewValidationError("user id and content are required")
+	}
+	if len(post.Content) > domain.MaxPostLength {
+		return domain.NewValidationError("post is too long")
+	}
+	if post.Visibility == "" {
+		post.Visibility = domain.VisibilityPublic
+	}
+	if !post.Visibility.Valid() {
+		return domain.NewValidationError("invalid visibility")
+	}
+	if post.RepostOfID != nil {
+		return domain.NewValidationError("reposts have no content of their own")
 	}
 
 	// Verify user exists
 	_, err := u.userRepo.GetByID(post.UserID.String())
 	if err != nil {
-		return errors.New("user not found")
+		return err
+	}
+
+	var quoted *domain.Post
+	if post.QuoteOfID != nil {
+		if quoted, err = u.shareable(post.UserID.String(), post.QuoteOfID.String()); err != nil {
+			return err
+		}
+		post.QuoteOfID = &quoted.ID
+	}
+
+	if err := u.validateMedia(post.UserID.String(), post.Media); err != nil {
+		return err
+	}
+
+	if err := u.postRepo.Create(post); err != nil {
+		return err
+	}
+	post.Hashtags = domain.ParseHashtags(post.Content)
+	if err := u.hashtagRepo.SetPostHashtags(post, post.Hashtags); err != nil {
+		return err
+	}
+	if err := u.setMentions(post); err != nil {
+		return err
+	}
+	if err := u.attachMedia(post); err != nil {
+		return err
 	}
 
-	return u.postRepo.Create(post)
+	// Subscribers get their own copy, as the caller goes on to serialize post
+	published := *post
+	u.events.Publish(domain.Event{
+		Type:    domain.EventPostCreated,
+		ActorID: post.UserID,
+		PostID:  post.ID,
+		Post:    &published,
+	})
+	if quoted != nil {
+		u.events.Publish(domain.Event{
+			Type:    domain.EventPostQuoted,
+			ActorID: post.UserID,
+			UserID:  quoted.UserID,
+			PostID:  post.ID,
+		})
+	}
+	return nil
 }
 
 func (u *postUseCase) UpdatePost(post *domain.Post) error {
 	if post.ID == uuid.Nil {
-		return errors.New("invalid post id")
+		return domain.NewValidationError("invalid post id")
 	}
 
-	existingPost, err := u.postRepo.GetByID(post.ID.String())
+	existingPost, err := u.postRepo.GetByID("", post.ID.String())
 	if err != nil {
 		return err
 	}
 
 	// Verify ownership
 	if existingPost.UserID != post.UserID {
-		return errors.New("unauthorized to update this post")
+		return domain.NewForbiddenError("unauthorized to update this post")
+	}
+	if existingPost.RepostOfID != nil {
+		return domain.NewValidationError("reposts cannot be edited")
+	}
+	if u.editWindow > 0 && time.Since(existingPost.CreatedAt) > u.editWindow {
+		return domain.NewForbiddenError("post can no longer be edited")
+	}
+
+	if strings.TrimSpace(post.Content) == "" {
+		return domain.NewValidationError("content is required")
+	}
+	if len(post.Content) > domain.MaxPostLength {
+		return domain.NewValidationError("post is too long")
+	}
+	if err := u.validateMedia(post.UserID.String(), post.Media); err != nil {
+		return err
+	}
+	if post.Visibility != "" && !post.Visibility.Valid() {
+		return domain.NewValidationError("invalid visibility")
 	}
 
-	// Update allowed fields
+	// Update allowed fields; visibility is kept unless given
 	existingPost.Content = post.Content
 	existingPost.Media = post.Media
+	if post.Visibility != "" {
+		existingPost.Visibility = post.Visibility
+	}
 
-	return u.postRepo.Update(existingPost)
+	if err := u.postRepo.Update(existingPost); err != nil {
+		return err
+	}
+	if err := u.hashtagRepo.SetPostHashtags(existingPost, domain.ParseHashtags(existingPost.Content)); err != nil {
+		return err
+	}
+	if err := u.setMentions(existingPost); err != nil {
+		return err
+	}
+
+	// Hand the stored post back to the caller
+	*post = *existingPost
+	return u.decorate(post.UserID.String(), post)
 }
 
-func (u *postUseCase) DeletePost(id string) error {
+func (u *postUseCase) GetRevisions(viewerID string, id string) ([]*domain.PostVersion, error) {
 	if id == "" {
-		return errors.New("invalid post id")
+		return nil, domain.NewValidationError("invalid post id")
 	}
+
+	post, err := u.postRepo.GetByID(viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := u.postRepo.GetRevisions(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPostVersions(revisions, post), nil
+}
+
+func (u *postUseCase) DeletePost(actorID string, id string) error {
+	if id == "" {
+		return domain.NewValidationError("invalid post id")
+	}
+
+	post, err := u.postRepo.GetByID("", id)
+	if err != nil {
+		return err
+	}
+
+	// Authors may delete their own posts, moderators anyone's
+	if post.UserID.String() != actorID {
+		actor, err := u.userRepo.GetByID(actorID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		if actor == nil || !actor.Role.Can(domain.PermModeratePosts) {
+			return domain.NewForbiddenError("unauthorized to delete this post")
+		}
+	}
+
 	return u.postRepo.Delete(id)
 }
 
-func (u *postUseCase) GetFeed(page int, limit int) ([]*domain.Post, error) {
-	if page < 1 {
-		page = 1
+func (u *postUseCase) Repost(userID string, postID string) (*domain.Post, error) {
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	original, err := u.shareable(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	reposted, err := u.postRepo.GetRepostedPostIDs(userID, []uuid.UUID{original.ID})
+	if err != nil {
+		return nil, err
+	}
+	if reposted[original.ID] {
+		return nil, domain.NewConflictError("post already reposted")
+	}
+
+	repost := &domain.Post{
+		UserID:     userUID,
+		Visibility: domain.VisibilityPublic,
+		RepostOfID: &original.ID,
+	}
+	if err := u.postRepo.Create(repost); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, domain.NewConflictError("post already reposted")
+		}
+		return nil, err
+	}
+
+	published := *repost
+	u.events.Publish(domain.Event{
+		Type:    domain.EventPostCreated,
+		ActorID: userUID,
+		PostID:  repost.ID,
+		Post:    &published,
+	})
+	u.events.Publish(domain.Event{
+		Type:    domain.EventPostReposted,
+		ActorID: userUID,
+		UserID:  original.UserID,
+		PostID:  original.ID,
+	})
+
+	if err := u.decorate(userID, repost); err != nil {
+		return nil, err
+	}
+	return repost, nil
+}
+
+func (u *postUseCase) Unrepost(userID string, postID string) error {
+	if userID == "" || postID == "" {
+		return domain.NewValidationError("invalid post id")
+	}
+	return u.postRepo.DeleteRepost(userID, postID)
+}
+
+func (u *postUseCase) GetHomeFeed(userID string, query domain.PageQuery) (*domain.PostPage, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	query = normalizePageQuery(query)
+	posts, err := u.postRepo.GetHomeFeed(userID, probe(query))
+	if err != nil {
+		return nil, err
+	}
+
+	page := newPostPage(posts, query)
+	if err := u.decorate(userID, page.Posts...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// SearchPosts returns posts matching text, most relevant and recent first.
+// Search results only page forward, so the page has no prev cursor.
+func (u *postUseCase) SearchPosts(viewerID string, text string, cursor *domain.SearchCursor, limit int) (*domain.PostPage, error) {
+	terms, err := domain.ParseSearchQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = normalizePageQuery(domain.PageQuery{Limit: limit}).Limit
+	hits, err := u.postRepo.Search(domain.PostSearchQuery{
+		Terms:    terms,
+		Cursor:   cursor,
+		Limit:    limit + 1,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.PostPage{Posts: []*domain.Post{}}
+	if len(hits) > limit {
+		hits = hits[:limit]
+		last := hits[len(hits)-1]
+		next := &domain.SearchCursor{Score: last.Score, ID: last.Post.ID}
+		page.NextCursor = next.Encode()
+	}
+	for _, hit := range hits {
+		page.Posts = append(page.Posts, hit.Post)
+	}
+
+	if err := u.decorate(viewerID, page.Posts...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetHashtagPosts returns the posts tagged with tag, newest first.
+func (u *postUseCase) GetHashtagPosts(viewerID string, tag string, query domain.PageQuery) (*domain.PostPage, error) {
+	tag = domain.NormalizeHashtag(tag)
+	if tag == "" {
+		return nil, domain.NewValidationError("hashtag is required")
+	}
+
+	query = normalizePageQuery(query)
+	posts, err := u.postRepo.GetByHashtag(viewerID, tag, probe(query))
+	if err != nil {
+		return nil, err
+	}
+
+	page := newPostPage(posts, query)
+	if err := u.decorate(viewerID, page.Posts...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetMentions pages through the posts and comments mentioning the user by
+// others, most recent first.
+func (u *postUseCase) GetMentions(userID string, query domain.PageQuery) (*domain.MentionPage, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	query = normalizePageQuery(query)
+	mentions, err := u.mentionRepo.GetByUserID(userID, probe(query))
+	if err != nil {
+		return nil, err
+	}
+	mentions, next, prev := trimPage(mentions, query, func(mention *domain.Mention) (time.Time, uuid.UUID) {
+		return mention.CreatedAt, mention.ID
+	})
+
+	var postIDs, commentIDs []uuid.UUID
+	for _, mention := range mentions {
+		postIDs = append(postIDs, mention.PostID)
+		if mention.CommentID != nil {
+			commentIDs = append(commentIDs, *mention.CommentID)
+		}
+	}
+
+	posts, err := u.postRepo.GetByIDs(userID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.decorate(userID, posts...); err != nil {
+		return nil, err
+	}
+	comments, err := u.commentRepo.GetByIDs(commentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachCommentMentions(u.mentionRepo, comments...); err != nil {
+		return nil, err
+	}
+
+	postsByID := make(map[uuid.UUID]*domain.Post, len(posts))
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+	commentsByID := make(map[uuid.UUID]*domain.Comment, len(comments))
+	for _, comment := range comments {
+		commentsByID[comment.ID] = comment
+	}
+	// The posts are read for the user, so mentions in posts the user may not
+	// see, such as comments under a blocked user's post, are left out
+	visible := make([]*domain.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		mention.Post = postsByID[mention.PostID]
+		if mention.Post == nil {
+			continue
+		}
+		if mention.CommentID != nil {
+			if mention.Comment = commentsByID[*mention.CommentID]; mention.Comment == nil {
+				continue
+			}
+		}
+		visible = append(visible, mention)
+	}
+
+	return &domain.MentionPage{Mentions: visible, NextCursor: next, PrevCursor: prev}, nil
+}
+
+// decorate fills in the computed fields of posts for the viewer, including
+// the posts they repost or quote.
+func (u *postUseCase) decorate(viewerID string, posts ...*domain.Post) error {
+	shared, err := u.attachShared(viewerID, posts...)
+	if err != nil {
+		return err
+	}
+	posts = slices.Concat(posts, shared)
+
+	for _, post := range posts {
+		post.Hashtags = domain.ParseHashtags(post.Content)
+	}
+	if err := u.attachLikes(viewerID, posts...); err != nil {
+		return err
+	}
+	if err := u.attachReposts(viewerID, posts...); err != nil {
+		return err
+	}
+	if err := u.attachMentions(posts...); err != nil {
+		return err
+	}
+	return u.attachMedia(posts...)
+}
+
+// setMentions records the users the post mentions and where, and lets the
+// newly mentioned know.
+func (u *postUseCase) setMentions(post *domain.Post) error {
+	users, err := resolveMentions(u.userRepo, post.Content)
+	if err != nil {
+		return err
+	}
+	added, err := u.mentionRepo.SetPostMentions(post, userIDs(users))
+	if err != nil {
+		return err
+	}
+	post.Mentions = domain.MentionEntities(post.Content, users)
+
+	for _, userID := range added {
+		u.events.Publish(domain.Event{
+			Type:    domain.EventUserMentioned,
+			ActorID: post.UserID,
+			UserID:  userID,
+			PostID:  post.ID,
+		})
+	}
+	return nil
+}
+
+// attachMentions fills in where each post mentions users.
+func (u *postUseCase) attachMentions(posts ...*domain.Post) error {
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	mentioned, err := u.mentionRepo.GetPostMentions(ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.Mentions = domain.MentionEntities(post.Content, mentioned[post.ID])
+	}
+	return nil
+}
+
+// attachLikes fills in the like count of each post and whether the viewer
+// has liked it.
+func (u *postUseCase) attachLikes(viewerID string, posts ...*domain.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	counts, err := u.likeRepo.CountByPostIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	liked := map[uuid.UUID]bool{}
+	if viewerID != "" {
+		if liked, err = u.likeRepo.GetLikedPostIDs(viewerID, ids); err != nil {
+			return err
+		}
+	}
+
+	for _, post := range posts {
+		post.LikeCount = counts[post.ID]
+		post.LikedByMe = liked[post.ID]
+	}
+	return nil
+}
+
+// shareable returns the post the user reposts or quotes when picking postID,
+// which is the original for reposts. Users may share their own posts and
+// public posts of public accounts they can see.
+func (u *postUseCase) shareable(userID string, postID string) (*domain.Post, error) {
+	post, err := u.postRepo.GetByID(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.RepostOfID != nil {
+		if post, err = u.postRepo.GetByID(userID, post.RepostOfID.String()); err != nil {
+			return nil, err
+		}
+	}
+	if post.UserID.String() == userID {
+		return post, nil
+	}
+
+	if post.Visibility != domain.VisibilityPublic {
+		return nil, domain.NewForbiddenError("only public posts can be shared")
+	}
+	author, err := u.userRepo.GetByID(post.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+	if author.IsPrivate {
+		return nil, domain.NewForbiddenError("only public posts can be shared")
+	}
+	return post, nil
+}
+
+// attachShared fills in the posts that posts repost or quote and returns
+// them. Those deleted or hidden from the viewer are left out.
+func (u *postUseCase) attachShared(viewerID string, posts ...*domain.Post) ([]*domain.Post, error) {
+	var ids []uuid.UUID
+	for _, post := range posts {
+		if post.RepostOfID != nil {
+			ids = append(ids, *post.RepostOfID)
+		}
+		if post.QuoteOfID != nil {
+			ids = append(ids, *post.QuoteOfID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	shared, err := u.postRepo.GetByIDs(viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*domain.Post, len(shared))
+	for _, post := range shared {
+		byID[post.ID] = post
+	}
+	for _, post := range posts {
+		if post.RepostOfID != nil {
+			post.RepostOf = byID[*post.RepostOfID]
+		}
+		if post.QuoteOfID != nil {
+			post.QuoteOf = byID[*post.QuoteOfID]
+		}
+	}
+	return shared, nil
+}
+
+// attachReposts fills in the repost count of each post and whether the
+// viewer has reposted it.
+func (u *postUseCase) attachReposts(viewerID string, posts ...*domain.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	counts, err := u.postRepo.CountReposts(ids)
+	if err != nil {
+		return err
+	}
+
+	reposted := map[uuid.UUID]bool{}
+	if viewerID != "" {
+		if reposted, err = u.postRepo.GetRepostedPostIDs(viewerID, ids); err != nil {
+			return err
+		}
+	}
+
+	for _, post := range posts {
+		post.RepostCount = counts[post.ID]
+		post.RepostedByMe = reposted[post.ID]
+	}
+	return nil
+}
+
+// attachMedia fills in the sized versions of each post's media. Entries that
+// are not uploads are offered as-is in every size.
+func (u *postUseCase) attachMedia(posts ...*domain.Post) error {
+	var urls []string
+	for _, post := range posts {
+		urls = append(urls, post.Media...)
+	}
+	variants, err := resolveVariants(u.mediaRepo, urls)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.MediaVariants = make([]*domain.ImageVariants, len(post.Media))
+		for i, url := range post.Media {
+			if v, ok := variants[url]; ok {
+				post.MediaVariants[i] = v
+			} else {
+				post.MediaVariants[i] = &domain.ImageVariants{Thumbnail: url, Medium: url, Original: url}
+			}
+		}
+	}
+	return nil
+}
+
+// validateMedia checks that every media URL refers to an upload owned by the
+// author.
+func (u *postUseCase) validateMedia(userID string, media []string) error {
+	if len(media) == 0 {
+		return nil
+	}
+	owned, err := u.mediaRepo.GetOwnedURLs(userID, media)
+	if err != nil {
+		return err
 	}
-	if limit < 1 {
-		limit = 10
+	for _, url := range media {
+		if !owned[url] {
+			return domain.NewValidationError("media must reference your own uploads: " + url)
+		}
 	}
-	return u.postRepo.GetFeed(page, limit)
+	return nil
 }
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"socialnetwork/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// visiblePostRepository serves the posts the viewer may see; the methods it
+// does not override are not used by the tests.
+type visiblePostRepository struct {
+	domain.PostRepository
+	visible map[uuid.UUID]*domain.Post
+}
+
+func (r *visiblePostRepository) GetByIDs(viewerID string, ids []uuid.UUID) ([]*domain.Post, error) {
+	var posts []*domain.Post
+	for _, id := range ids {
+		if post, ok := r.visible[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *visiblePostRepository) CountReposts(postIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	return map[uuid.UUID]int64{}, nil
+}
+
+func (r *visiblePostRepository) GetRepostedPostIDs(userID string, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return map[uuid.UUID]bool{}, nil
+}
+
+type noLikeRepository struct {
+	domain.LikeRepository
+}
+
+func (r *noLikeRepository) CountByPostIDs(postIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	return map[uuid.UUID]int64{}, nil
+}
+
+func (r *noLikeRepository) GetLikedPostIDs(userID string, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return map[uuid.UUID]bool{}, nil
+}
+
+type listedMentionRepository struct {
+	domain.MentionRepository
+	mentions []*domain.Mention
+}
+
+func (r *listedMentionRepository) GetByUserID(userID string, query domain.PageQuery) ([]*domain.Mention, error) {
+	return r.mentions, nil
+}
+
+func (r *listedMentionRepository) GetPostMentions(postIDs []uuid.UUID) (map[uuid.UUID][]*domain.User, error) {
+	return map[uuid.UUID][]*domain.User{}, nil
+}
+
+func (r *listedMentionRepository) GetCommentMentions(commentIDs []uuid.UUID) (map[uuid.UUID][]*domain.User, error) {
+	return map[uuid.UUID][]*domain.User{}, nil
+}
+
+type listedCommentRepository struct {
+	domain.CommentRepository
+	comments map[uuid.UUID]*domain.Comment
+}
+
+func (r *listedCommentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+	for _, id := range ids {
+		if comment, ok := r.comments[id]; ok {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+func TestGetMentionsLeavesOutPostsTheUserMayNotSee(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+
+	visiblePost := &domain.Post{ID: uuid.New(), UserID: uuid.New(), Content: "hi @alice"}
+	hiddenPost := &domain.Post{ID: uuid.New(), UserID: uuid.New(), Content: "a post by someone alice blocked"}
+	hiddenComment := &domain.Comment{ID: uuid.New(), PostID: hiddenPost.ID, UserID: uuid.New(), Content: "hi @alice"}
+
+	visibleMention := &domain.Mention{ID: uuid.New(), UserID: userID, AuthorID: visiblePost.UserID, PostID: visiblePost.ID, CreatedAt: now}
+	hiddenMention := &domain.Mention{ID: uuid.New(), UserID: userID, AuthorID: hiddenComment.UserID, PostID: hiddenPost.ID, CommentID: &hiddenComment.ID, CreatedAt: now.Add(-time.Minute)}
+
+	posts := NewPostUseCase(
+		&visiblePostRepository{visible: map[uuid.UUID]*domain.Post{visiblePost.ID: visiblePost}},
+		nil,
+		&noLikeRepository{},
+		nil,
+		nil,
+		&listedMentionRepository{mentions: []*domain.Mention{visibleMention, hiddenMention}},
+		&listedCommentRepository{comments: map[uuid.UUID]*domain.Comment{hiddenComment.ID: hiddenComment}},
+		nil,
+		0,
+	)
+
+	page, err := posts.GetMentions(userID.String(), domain.PageQuery{})
+	if err != nil {
+		t.Fatalf("GetMentions: %v", err)
+	}
+	if len(page.Mentions) != 1 || page.Mentions[0].ID != visibleMention.ID {
+		t.Fatalf("GetMentions returned %d mentions, want only the one in the visible post", len(page.Mentions))
+	}
+	if page.Mentions[0].Post != visiblePost {
+		t.Error("the mention does not carry its post")
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"log"
+	"slices"
+	"socialnetwork/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Pusher forwards the events realtime clients care about to the users they
+// concern.
+type Pusher struct {
+	broker           domain.PushBroker
+	followRepo       domain.FollowRepository
+	conversationRepo domain.ConversationRepository
+	muteRepo         domain.MuteRepository
+}
+
+func NewPusher(broker domain.PushBroker, followRepo domain.FollowRepository, conversationRepo domain.ConversationRepository, muteRepo domain.MuteRepository) *Pusher {
+	return &Pusher{
+		broker:           broker,
+		followRepo:       followRepo,
+		conversationRepo: conversationRepo,
+		muteRepo:         muteRepo,
+	}
+}
+
+// Handle pushes event to its recipients. Failures are logged; clients catch
+// up by reloading.
+func (p *Pusher) Handle(event domain.Event) {
+	if err := p.handle(event); err != nil {
+		log.Printf("push: handling %s failed: %v", event.Type, err)
+	}
+}
+
+func (p *Pusher) handle(event domain.Event) error {
+	switch event.Type {
+	case domain.EventPostCreated:
+		// The post lands in the home feed of the author and of those followers
+		// who can see it, except those who muted the author
+		if event.Post.Visibility == domain.VisibilityPrivate {
+			return p.push([]uuid.UUID{event.ActorID}, domain.PushFeedPost, event.Post)
+		}
+		followers, err := p.followRepo.GetFollowerIDs(event.ActorID)
+		if err != nil {
+			return err
+		}
+		muters, err := p.muteRepo.GetMuterIDs(event.ActorID)
+		if err != nil {
+			return err
+		}
+		excluded := make(map[uuid.UUID]bool, len(muters))
+		for _, id := range muters {
+			excluded[id] = true
+		}
+		if event.Post.Visibility == domain.VisibilityMentioned {
+			mentioned := make(map[uuid.UUID]bool, len(event.Post.Mentions))
+			for _, mention := range event.Post.Mentions {
+				mentioned[mention.UserID] = true
+			}
+			for _, id := range followers {
+				if !mentioned[id] {
+					excluded[id] = true
+				}
+			}
+		}
+		followers = slices.DeleteFunc(followers, func(id uuid.UUID) bool {
+			return excluded[id]
+		})
+		return p.push(append(followers, event.ActorID), domain.PushFeedPost, event.Post)
+	case domain.EventMessageCreated:
+		// Senders get their own messages too, for their other devices
+		participants, err := p.conversationRepo.GetParticipantIDs(event.Message.ConversationID)
+		if err != nil {
+			return err
+		}
+		return p.push(participants, domain.PushDirectMessage, event.Message)
+	case domain.EventNotificationCreated:
+		return p.push([]uuid.UUID{event.UserID}, domain.PushNotification, event.Notification)
+	}
+	return nil
+}
+
+func (p *Pusher) push(userIDs []uuid.UUID, pushType domain.PushType, data interface{}) error {
+	message, err := domain.NewPushMessage(pushType, data)
+	if err != nil {
+		return err
+	}
+	return p.broker.Publish(&domain.Push{UserIDs: userIDs, Message: message})
+}
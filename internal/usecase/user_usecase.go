@@ -3,63 +3,212 @@ package usecase
 import (
 	"errors"
 	"socialnetwork/internal/domain"
+	"strings"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxUserQueryLength   = 100
+	maxAutocompleteLimit = 20
 )
 
 type userUseCase struct {
-	userRepo domain.UserRepository
+	userRepo  domain.UserRepository
+	mediaRepo domain.MediaRepository
+	blockRepo domain.BlockRepository
+	feeds     domain.FeedCache
 }
 
-func NewUserUseCase(userRepo domain.UserRepository) domain.UserUseCase {
+func NewUserUseCase(userRepo domain.UserRepository, mediaRepo domain.MediaRepository, blockRepo domain.BlockRepository, feeds domain.FeedCache) domain.UserUseCase {
 	return &userUseCase{
-		userRepo: userRepo,
+		userRepo:  userRepo,
+		mediaRepo: mediaRepo,
+		blockRepo: blockRepo,
+		feeds:     feeds,
 	}
 }
 
-func (u *userUseCase) GetUser(id string) (*domain.User, error) {
+func (u *userUseCase) GetUser(viewerID string, id string) (domain.UserView, error) {
 	if id == "" {
-		return nil, errors.New("invalid user id")
+		return nil, domain.NewValidationError("invalid user id")
+	}
+
+	user, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := u.getActor(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkVisible(viewer, user); err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, user); err != nil {
+		return nil, err
+	}
+	return domain.NewUserView(user, viewer), nil
+}
+
+func (u *userUseCase) GetUserByUsername(viewerID string, username string) (domain.UserView, error) {
+	username = strings.TrimPrefix(username, "@")
+	if username == "" {
+		return nil, domain.NewValidationError("invalid username")
+	}
+
+	user, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := u.getActor(viewerID)
+	if err != nil {
+		return nil, err
 	}
-	return u.userRepo.GetByID(id)
+	if err := u.checkVisible(viewer, user); err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, user); err != nil {
+		return nil, err
+	}
+	return domain.NewUserView(user, viewer), nil
 }
 
-func (u *userUseCase) CreateUser(user *domain.User) error {
-	if user.Email == "" || user.Username == "" {
-		return errors.New("email and username are required")
+func (u *userUseCase) SearchUsers(viewerID string, query string, page int, limit int) ([]*domain.PublicUser, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domain.NewValidationError("search query is required")
+	}
+	if len(query) > maxUserQueryLength {
+		return nil, domain.NewValidationError("search query is too long")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	users, err := u.userRepo.Search(viewerID, query, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+// AutocompleteUsers suggests users whose username starts with prefix. A
+// leading @ is ignored so mention typeahead can pass what was typed.
+func (u *userUseCase) AutocompleteUsers(viewerID string, prefix string, limit int) ([]*domain.PublicUser, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "@")
+	if prefix == "" {
+		return []*domain.PublicUser{}, nil
+	}
+	if len(prefix) > maxUserQueryLength {
+		return nil, domain.NewValidationError("prefix is too long")
+	}
+	if limit < 1 || limit > maxAutocompleteLimit {
+		limit = maxAutocompleteLimit
+	}
+
+	users, err := u.userRepo.SearchByPrefix(viewerID, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, users...); err != nil {
+		return nil, err
+	}
+	return domain.NewPublicUsers(users), nil
+}
+
+func (u *userUseCase) CreateUser(actorID string, req *domain.CreateUserRequest) (*domain.AdminUser, error) {
+	if _, err := u.requirePermission(actorID, domain.PermManageUsers); err != nil {
+		return nil, err
+	}
+
+	if req.Email == "" || req.Username == "" {
+		return nil, domain.NewValidationError("email and username are required")
+	}
+	if req.Role == "" {
+		req.Role = domain.RoleUser
+	}
+	if !req.Role.Valid() {
+		return nil, domain.NewValidationError("invalid role")
 	}
 
 	// Check if user with email already exists
-	existingUser, _ := u.userRepo.GetByEmail(user.Email)
+	existingUser, _ := u.userRepo.GetByEmail(req.Email)
 	if existingUser != nil {
-		return errors.New("email already registered")
+		return nil, domain.NewConflictError("email already registered")
 	}
 
-	return u.userRepo.Create(user)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: string(hashedPassword),
+		FullName: req.FullName,
+		Role:     req.Role,
+	}
+	if err := u.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return domain.NewAdminUser(user), nil
 }
 
-func (u *userUseCase) UpdateUser(user *domain.User) error {
-	if user.ID == uuid.Nil {
-		return errors.New("invalid user id")
+func (u *userUseCase) UpdateUser(actorID string, id string, req *domain.UserUpdateRequest) (domain.UserView, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domain.NewValidationError("invalid user id")
 	}
 
-	existingUser, err := u.userRepo.GetByID(user.ID.String())
+	existingUser, err := u.userRepo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	actor, err := u.authorize(actorID, existingUser)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.validateAvatar(existingUser, req.Avatar); err != nil {
+		return nil, err
 	}
 
 	// Update only allowed fields
-	existingUser.FullName = user.FullName
-	existingUser.Bio = user.Bio
-	existingUser.Avatar = user.Avatar
+	wasPrivate := existingUser.IsPrivate
+	existingUser.FullName = req.FullName
+	existingUser.Bio = req.Bio
+	existingUser.Avatar = req.Avatar
+	if req.IsPrivate != nil {
+		existingUser.IsPrivate = *req.IsPrivate
+	}
 
-	return u.userRepo.Update(existingUser)
+	if err := u.update(existingUser, wasPrivate); err != nil {
+		return nil, err
+	}
+	if err := attachAvatars(u.mediaRepo, existingUser); err != nil {
+		return nil, err
+	}
+	return domain.NewUserView(existingUser, actor), nil
 }
 
-func (u *userUseCase) PatchUser(id string, patch *domain.UserPatchRequest) error {
+func (u *userUseCase) PatchUser(actorID string, id string, patch *domain.UserPatchRequest) error {
 	_, err := uuid.Parse(id)
 	if err != nil {
-		return errors.New("invalid user id")
+		return domain.NewValidationError("invalid user id")
 	}
 
 	existingUser, err := u.userRepo.GetByID(id)
@@ -67,7 +216,12 @@ func (u *userUseCase) PatchUser(id string, patch *domain.UserPatchRequest) error
 		return err
 	}
 
+	if _, err := u.authorize(actorID, existingUser); err != nil {
+		return err
+	}
+
 	// Apply patches only if they are present in the request
+	wasPrivate := existingUser.IsPrivate
 	if patch.FullName != nil {
 		existingUser.FullName = *patch.FullName
 	}
@@ -75,15 +229,123 @@ func (u *userUseCase) PatchUser(id string, patch *domain.UserPatchRequest) error
 		existingUser.Bio = *patch.Bio
 	}
 	if patch.Avatar != nil {
+		if err := u.validateAvatar(existingUser, *patch.Avatar); err != nil {
+			return err
+		}
 		existingUser.Avatar = *patch.Avatar
 	}
+	if patch.IsPrivate != nil {
+		existingUser.IsPrivate = *patch.IsPrivate
+	}
 
-	return u.userRepo.Update(existingUser)
+	return u.update(existingUser, wasPrivate)
 }
 
-func (u *userUseCase) DeleteUser(id string) error {
+// update saves the user. Making the account private or public changes who
+// sees its posts in every feed, so cached ones are dropped.
+func (u *userUseCase) update(user *domain.User, wasPrivate bool) error {
+	if err := u.userRepo.Update(user); err != nil {
+		return err
+	}
+	if user.IsPrivate != wasPrivate {
+		u.feeds.InvalidateAll()
+	}
+	return nil
+}
+
+func (u *userUseCase) DeleteUser(actorID string, id string) error {
 	if id == "" {
-		return errors.New("invalid user id")
+		return domain.NewValidationError("invalid user id")
+	}
+
+	existingUser, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.authorize(actorID, existingUser); err != nil {
+		return err
 	}
+
 	return u.userRepo.Delete(id)
 }
+
+func (u *userUseCase) SetRole(actorID string, id string, role domain.Role) error {
+	if !role.Valid() {
+		return domain.NewValidationError("invalid role")
+	}
+
+	existingUser, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.requirePermission(actorID, domain.PermManageRoles); err != nil {
+		return err
+	}
+
+	existingUser.Role = role
+	return u.userRepo.Update(existingUser)
+}
+
+// validateAvatar checks that a new avatar is an upload owned by the user.
+// Clearing the avatar or keeping the current one is always allowed.
+func (u *userUseCase) validateAvatar(user *domain.User, avatar string) error {
+	if avatar == "" || avatar == user.Avatar {
+		return nil
+	}
+	owned, err := u.mediaRepo.GetOwnedURLs(user.ID.String(), []string{avatar})
+	if err != nil {
+		return err
+	}
+	if !owned[avatar] {
+		return domain.NewValidationError("avatar must reference your own upload")
+	}
+	return nil
+}
+
+// authorize allows the account owner, or anyone allowed to manage users, to
+// modify the target account. It returns the acting user.
+func (u *userUseCase) authorize(actorID string, target *domain.User) (*domain.User, error) {
+	if actorID == target.ID.String() {
+		return target, nil
+	}
+	return u.requirePermission(actorID, domain.PermManageUsers)
+}
+
+func (u *userUseCase) requirePermission(actorID string, permission domain.Permission) (*domain.User, error) {
+	actor, err := u.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil {
+		return nil, domain.NewForbiddenError("unknown account")
+	}
+	if !actor.Role.Can(permission) {
+		return nil, domain.NewForbiddenError("missing permission " + string(permission))
+	}
+	return actor, nil
+}
+
+// checkVisible hides users who blocked, or were blocked by, the viewer.
+func (u *userUseCase) checkVisible(viewer *domain.User, user *domain.User) error {
+	if viewer == nil {
+		return nil
+	}
+	return checkNotBlocked(u.blockRepo, viewer.ID, user.ID, "user not found")
+}
+
+// getActor loads the user behind a request, or nil when there is none.
+func (u *userUseCase) getActor(actorID string) (*domain.User, error) {
+	if actorID == "" {
+		return nil, nil
+	}
+	actor, err := u.userRepo.GetByID(actorID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
@@ -0,0 +1,2 @@
+-- The legacy schema is not restored; nothing to undo.
+SELECT 1;
@@ -0,0 +1,17 @@
+-- Databases created from the removed 001_create_tables.sql script skipped the
+-- CREATE TABLE IF NOT EXISTS statements of 000001 and 000002. Bring them in
+-- line with the numbered migrations; on other databases this is a no-op.
+ALTER TABLE users ALTER COLUMN username TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN full_name TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN avatar TYPE TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE posts ALTER COLUMN media SET DEFAULT '{}';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+-- Superseded by post_likes
+ALTER TABLE posts DROP COLUMN IF EXISTS likes;
+
+-- updated_at is maintained by the application
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+DROP TRIGGER IF EXISTS update_posts_updated_at ON posts;
+DROP FUNCTION IF EXISTS update_updated_at_column();
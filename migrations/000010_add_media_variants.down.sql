@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_media_processing;
+DROP INDEX IF EXISTS idx_media_url;
+ALTER TABLE media DROP COLUMN IF EXISTS medium_url;
+ALTER TABLE media DROP COLUMN IF EXISTS thumbnail_url;
+ALTER TABLE media DROP COLUMN IF EXISTS status;
@@ -0,0 +1,7 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ready'
+    CHECK (status IN ('processing', 'ready', 'failed'));
+ALTER TABLE media ADD COLUMN IF NOT EXISTS thumbnail_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS medium_url TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_media_url ON media(url);
+CREATE INDEX IF NOT EXISTS idx_media_processing ON media(created_at) WHERE status = 'processing';
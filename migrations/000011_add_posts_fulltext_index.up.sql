@@ -0,0 +1,2 @@
+-- Must match the expression used by the post search query
+CREATE INDEX IF NOT EXISTS idx_posts_content_fts ON posts USING GIN (to_tsvector('english', content)) WHERE deleted_at IS NULL;
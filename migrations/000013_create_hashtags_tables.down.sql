@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS hashtag_trends;
+DROP TABLE IF EXISTS post_hashtags;
+DROP TABLE IF EXISTS hashtags;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS hashtags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tag VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS post_hashtags (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    hashtag_id UUID NOT NULL REFERENCES hashtags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (post_id, hashtag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_hashtags_hashtag_id_created_at ON post_hashtags(hashtag_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_post_hashtags_created_at ON post_hashtags(created_at);
+
+-- Rebuilt by the trending job
+CREATE TABLE IF NOT EXISTS hashtag_trends (
+    hashtag_id UUID PRIMARY KEY REFERENCES hashtags(id) ON DELETE CASCADE,
+    uses_1h BIGINT NOT NULL,
+    uses_24h BIGINT NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_hashtag_trends_score ON hashtag_trends(score DESC);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS mentions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A post or comment mentions each user at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mentions_post_user ON mentions(post_id, user_id) WHERE comment_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mentions_comment_user ON mentions(comment_id, user_id) WHERE comment_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_mentions_user_id_created_at ON mentions(user_id, created_at DESC, id DESC);
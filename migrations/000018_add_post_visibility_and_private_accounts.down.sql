@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS follow_requests;
+ALTER TABLE users DROP COLUMN IF EXISTS is_private;
+ALTER TABLE posts DROP COLUMN IF EXISTS visibility;
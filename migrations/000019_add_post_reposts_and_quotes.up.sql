@@ -0,0 +1,10 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS repost_of_id UUID REFERENCES posts(id);
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS quote_of_id UUID REFERENCES posts(id);
+ALTER TABLE posts ADD CONSTRAINT posts_repost_or_quote_check
+    CHECK (repost_of_id IS NULL OR quote_of_id IS NULL);
+
+-- A user reposts a post at most once, until they undo it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_user_id_repost_of_id ON posts(user_id, repost_of_id)
+    WHERE repost_of_id IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_repost_of_id ON posts(repost_of_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_quote_of_id ON posts(quote_of_id);
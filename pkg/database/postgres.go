@@ -28,7 +28,10 @@ func NewPostgresConnection(config *PostgresConfig) (*gorm.DB, error) {
 		config.SSLMode,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		// Report constraint violations as gorm.ErrDuplicatedKey and friends
+		TranslateError: true,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
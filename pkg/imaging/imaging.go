@@ -0,0 +1,90 @@
+// Package imaging decodes, orients, resizes and re-encodes uploaded images.
+// Re-encoding drops all metadata, including EXIF location data.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the size of images that are decoded, so a small file
+// declaring huge dimensions cannot exhaust memory.
+const MaxPixels = 40_000_000
+
+const jpegQuality = 85
+
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Decode decodes data and applies its EXIF orientation, returning the image
+// and the name of its format ("jpeg", "png", ...).
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Fit scales img down so neither side exceeds maxEdge, keeping its aspect
+// ratio. Images that already fit are returned unchanged.
+func Fit(img image.Image, maxEdge int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxEdge && h <= maxEdge {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*maxEdge/w)
+		w = maxEdge
+	} else {
+		w = max(1, w*maxEdge/h)
+		h = maxEdge
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img as PNG when it came from a PNG or has transparency, and
+// as JPEG otherwise. It returns the encoded bytes, their content type and the
+// matching file extension.
+func Encode(img image.Image, sourceFormat string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if sourceFormat == "png" || !isOpaque(img) {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode png: %w", err)
+		}
+		return buf.Bytes(), "image/png", ".png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, "", "", fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return buf.Bytes(), "image/jpeg", ".jpg", nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}